@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// ctrlC is the byte a terminal in raw mode reads for Ctrl+C
+const ctrlC = 0x03
+
+// loopMonitor records when the event loop started handling a message, so a
+// stuck event loop can be told apart from one waiting for messages
+type loopMonitor struct {
+	busySince atomic.Int64 // Unix nanoseconds, 0 while waiting
+}
+
+// enter marks the event loop as busy
+func (l *loopMonitor) enter() {
+	l.busySince.Store(time.Now().UnixNano())
+}
+
+// leave marks the event loop as waiting again
+func (l *loopMonitor) leave() {
+	l.busySince.Store(0)
+}
+
+// stuck reports whether the event loop has been busy for longer than a user
+// would wait before pressing Ctrl+C again
+func (l *loopMonitor) stuck() bool {
+	since := l.busySince.Load()
+	return since != 0 && time.Since(time.Unix(0, since)) > tui.ForceQuitWindow
+}
+
+// monitoredModel reports to a loopMonitor while the model updates and renders,
+// which is all the event loop does
+type monitoredModel struct {
+	tea.Model
+	monitor *loopMonitor
+}
+
+// Update updates the wrapped model
+func (m monitoredModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.monitor.enter()
+	defer m.monitor.leave()
+
+	model, cmd := m.Model.Update(msg)
+	return monitoredModel{Model: model, monitor: m.monitor}, cmd
+}
+
+// View renders the wrapped model
+func (m monitoredModel) View() string {
+	m.monitor.enter()
+	defer m.monitor.leave()
+	return m.Model.View()
+}
+
+// terminalRestorer returns a function that puts the terminal back the way it
+// was before the program started, for exiting without the program's help
+func terminalRestorer(altScreen bool) func() {
+	fd := int(os.Stdin.Fd())
+	state, err := term.GetState(fd)
+
+	return func() {
+		if err == nil {
+			_ = term.Restore(fd, state)
+		}
+
+		output := termenv.NewOutput(os.Stdout)
+		if altScreen {
+			output.ExitAltScreen()
+		}
+		output.ShowCursor()
+	}
+}
+
+// forceQuitWhenStuck reads Ctrl+C from the terminal itself while the event
+// loop is stuck, since bubbletea can't deliver key presses to the model then,
+// and exits after restoring the terminal with restore. The first key press
+// may still be read by bubbletea, so like the installer's own force quit, it
+// takes pressing Ctrl+C twice.
+func forceQuitWhenStuck(monitor *loopMonitor, restore func()) {
+	ticker := time.NewTicker(tui.ForceQuitWindow / 4)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !monitor.stuck() {
+			continue
+		}
+
+		interrupted, err := readInterrupt(monitor)
+		if err != nil {
+			return
+		}
+		if interrupted {
+			restore()
+			aur.Cleanup()
+			fmt.Println("Force quit")
+			os.Exit(130)
+		}
+	}
+}
+
+// readInterrupt reads the terminal until Ctrl+C is pressed or the event loop
+// is no longer stuck. Other key presses are dropped, the model wouldn't get
+// them anyway.
+func readInterrupt(monitor *loopMonitor) (bool, error) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false, err
+	}
+	defer tty.Close()
+
+	buf := make([]byte, 64)
+	for monitor.stuck() {
+		// Stop reading once the event loop is back to handle key presses
+		if err := tty.SetReadDeadline(time.Now().Add(tui.ForceQuitWindow / 4)); err != nil {
+			return false, err
+		}
+
+		n, err := tty.Read(buf)
+		if bytes.IndexByte(buf[:n], ctrlC) >= 0 {
+			return true, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			return false, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+)
+
+// catalog is the JSON form of --list-packages. Categories use the format of
+// the --categories file.
+type catalog struct {
+	BasePackages []string                 `json:"base_packages"`
+	Categories   []config.PackageCategory `json:"categories"`
+}
+
+// listPackages prints the base packages and the package categories, as JSON
+// when asJSON is set, and returns the exit code
+func listPackages(categories []config.PackageCategory, asJSON bool) int {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(catalog{BasePackages: config.BasePackages, Categories: categories}); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		return 0
+	}
+
+	writeCatalog(os.Stdout, categories)
+	return 0
+}
+
+// writeCatalog writes the base packages and categories in a readable form
+func writeCatalog(w io.Writer, categories []config.PackageCategory) {
+	fmt.Fprintf(w, "Base packages (always installed, %d):\n", len(config.BasePackages))
+	writeWrapped(w, "  ", config.BasePackages)
+
+	for _, category := range categories {
+		fmt.Fprintf(w, "\n%s: %s\n", category.Name, category.Description)
+		for _, option := range category.Options {
+			// Mark the options selected by default like the checkboxes of the UI
+			mark := "[ ]"
+			if option.Default {
+				mark = "[x]"
+			}
+
+			var notes []string
+			if option.Experimental() {
+				notes = append(notes, string(config.StabilityExperimental))
+			} else if option.Stability == config.StabilityRecommended {
+				notes = append(notes, string(config.StabilityRecommended))
+			}
+			if buildTime := option.EstimatedBuildTime(); buildTime == config.BuildTimeMedium || buildTime == config.BuildTimeSlow {
+				notes = append(notes, string(buildTime)+" build")
+			}
+			notes = append(notes, option.Tags...)
+
+			fmt.Fprintf(w, "  %s %s - %s", mark, option.Name, option.Description)
+			if len(notes) > 0 {
+				fmt.Fprintf(w, " (%s)", strings.Join(notes, ", "))
+			}
+			fmt.Fprintln(w)
+			writeWrapped(w, "      ", option.Packages)
+		}
+	}
+}
+
+// listWidth is the width package lists are wrapped at
+const listWidth = 80
+
+// writeWrapped writes words separated by spaces, starting each line with
+// indent and wrapping lines at listWidth
+func writeWrapped(w io.Writer, indent string, words []string) {
+	line := indent
+	for _, word := range words {
+		if line != indent && len(line)+1+len(word) > listWidth {
+			fmt.Fprintln(w, line)
+			line = indent
+		}
+		if line != indent {
+			line += " "
+		}
+		line += word
+	}
+	fmt.Fprintln(w, line)
+}
@@ -1,23 +1,204 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// gitTokenEnv is the environment variable --git-token defaults to. It is read
+// after parsing so the token doesn't show up in the --help output.
+const gitTokenEnv = "LUNARIS_GIT_TOKEN"
+
 func main() {
-	// Create a new model
-	m := tui.NewModel()
+	// Load defaults from the config file, command line flags override them
+	opts, err := config.LoadDefaults()
+	if err != nil {
+		fmt.Println("Error loading config file:", err)
+		os.Exit(1)
+	}
+
+	// Parse command line flags
+	flag.BoolVar(&opts.AssumeYes, "yes", opts.AssumeYes, "Automatically accept all confirmation prompts")
+	flag.BoolVar(&opts.AssumeYes, "y", opts.AssumeYes, "Shorthand for --yes")
+	flag.BoolVar(&opts.Force, "force", opts.Force, "Allow destructive operations without confirmation when used with --yes")
+	flag.BoolVar(&opts.Resume, "resume", opts.Resume, "Resume an interrupted installation with the packages it had left")
+	flag.StringVar(&opts.SummaryPath, "summary", opts.SummaryPath, "Write a JSON summary of the run to this path (default ~/.cache/lunaris-installer/summary.json)")
+	flag.StringVar(&opts.AURHelper, "aur-helper", opts.AURHelper, "Preselect an AUR helper (yay, paru or none)")
+	flag.StringVar(&opts.LogPath, "log", opts.LogPath, "Append all command output to this file")
+	flag.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "Maximum duration of a single install command")
+	flag.DurationVar(&opts.IdleTimeout, "idle-timeout", opts.IdleTimeout, "Abort the installation after this long without progress or key presses, 0 to wait forever")
+	flag.BoolVar(&opts.Bell, "bell", opts.Bell, "Ring the terminal bell and send a desktop notification when the sudo password is needed")
+	flag.BoolVar(&opts.CheckAvailability, "check-availability", opts.CheckAvailability, "Check that the selected packages exist in the repositories or the AUR before installing")
+	flag.BoolVar(&opts.Animations, "animations", opts.Animations, "Enable page transition animations")
+	flag.BoolVar(&opts.NoAltScreen, "no-altscreen", opts.NoAltScreen, "Render inline instead of on the alternate screen, for terminals that leave artifacts")
+	flag.BoolVar(&opts.ASCII, "ascii", opts.ASCII, "Use ASCII markers and a high-contrast black-and-white palette")
+	flag.Func("spinner", "Spinner style: "+strings.Join(config.SpinnerStyles, ", ")+" (default dot, or line with --ascii)", func(value string) error {
+		if !config.ValidSpinner(value) {
+			return fmt.Errorf("must be one of %s", strings.Join(config.SpinnerStyles, ", "))
+		}
+		opts.Spinner = value
+		return nil
+	})
+	flag.Func("services", "Comma-separated systemd services to enable after installation (default "+strings.Join(opts.Services, ",")+")", func(value string) error {
+		opts.Services = config.ParseList(value)
+		return nil
+	})
+	flag.BoolVar(&opts.NoReclone, "no-reclone", opts.NoReclone, "Pull an existing ~/HyprLuna checkout instead of cloning it again")
+	flag.BoolVar(&opts.UseExisting, "use-existing", opts.UseExisting, "Install the dotfiles from an existing ~/HyprLuna as it is, without cloning or updating it")
+	flag.BoolVar(&opts.FullClone, "full-clone", opts.FullClone, "Clone ~/HyprLuna with its full history and all branches instead of only the latest commit")
+	flag.StringVar(&opts.Repo, "repo", opts.Repo, "Clone the configuration from this repository; git@ URLs are cloned over SSH")
+	flag.StringVar(&opts.GitToken, "git-token", "", "Token for cloning a private repository over HTTPS (default $"+gitTokenEnv+")")
+	flag.Func("backup-label", "Label the backup of the existing configuration, e.g. before-hyprluna; with --uninstall, restore the newest backup with this label", func(value string) error {
+		opts.BackupLabel = config.SanitizeBackupLabel(value)
+		if opts.BackupLabel == "" {
+			return fmt.Errorf("must contain letters or digits")
+		}
+		return nil
+	})
+	flag.BoolVar(&opts.CleanRepo, "clean-repo", opts.CleanRepo, "Delete ~/HyprLuna after the configuration has been copied")
+	flag.StringVar(&opts.CategoriesPath, "categories", opts.CategoriesPath, "Load additional package categories from this JSON file")
+	flag.BoolVar(&opts.DownloadFirst, "download-first", opts.DownloadFirst, "Download all packages from the official repositories before installing any of them")
+	flag.BoolVar(&opts.Strict, "strict", opts.Strict, "Abort on the first package that fails to install instead of continuing without it")
+	flag.BoolVar(&opts.SortDependencies, "sort-deps", opts.SortDependencies, "Install packages after the selected packages they depend on")
+	flag.BoolVar(&opts.Reinstall, "reinstall", opts.Reinstall, "Reinstall the selected packages even if they are up to date, to repair a broken setup")
+	flag.IntVar(&opts.MakeJobs, "make-jobs", opts.MakeJobs, "Number of parallel make and cargo jobs when building packages (default 2 for the AUR helper, 1 for packages)")
+	flag.IntVar(&opts.Nice, "nice", opts.Nice, "Niceness (0-19) package builds run with")
+	flag.BoolVar(&opts.NoIONice, "no-ionice", opts.NoIONice, "Don't lower the I/O priority of package builds")
+	flag.IntVar(&opts.MessageLimit, "max-messages", opts.MessageLimit, "Number of output messages kept in memory")
+	flag.StringVar(&opts.TruncationMessage, "truncation-message", opts.TruncationMessage, "Message shown in place of output dropped after --max-messages")
+	flag.Func("verbosity", "Command output shown: quiet (warnings, errors and successes only), normal or verbose (with debug output) (default normal)", func(value string) error {
+		if !config.ValidVerbosity(config.Verbosity(value)) {
+			return fmt.Errorf("must be quiet, normal or verbose")
+		}
+		opts.Verbosity = config.Verbosity(value)
+		return nil
+	})
+	flag.Float64Var(&opts.TruncationHead, "truncation-head", opts.TruncationHead, "Share of --max-messages kept from the start of the output when it is truncated")
+	flag.Func("extra", "Comma-separated extra packages to install, e.g. htop,btop", func(value string) error {
+		opts.ExtraPackages = config.ParseList(value)
+		return config.ValidatePackageNames(opts.ExtraPackages)
+	})
+	flag.Func("exclude", "Comma-separated packages never to install, even base packages, e.g. firefox,nano", func(value string) error {
+		opts.ExcludedPackages = config.ParseList(value)
+		return config.ValidatePackageNames(opts.ExcludedPackages)
+	})
+	listMode := flag.Bool("list-packages", false, "Print the base packages and package categories, then exit")
+	listJSON := flag.Bool("json", false, "Print --list-packages as JSON")
+	uninstallMode := flag.Bool("uninstall", false, "Remove the dotfiles recorded by previous installs, optionally restoring a backup")
+	flag.Parse()
+
+	if opts.GitToken == "" {
+		opts.GitToken = os.Getenv(gitTokenEnv)
+	}
+
+	if *uninstallMode {
+		os.Exit(uninstall(opts.AssumeYes, opts.BackupLabel))
+	}
+
+	if opts.MakeJobs < 0 || !config.ValidNice(opts.Nice) {
+		fmt.Println("Error: --make-jobs must not be negative and --nice must be between 0 and 19")
+		os.Exit(1)
+	}
+	if !config.ValidMessageLimit(opts.MessageLimit) || !config.ValidTruncationHead(opts.TruncationHead) {
+		fmt.Println("Error: --max-messages must be at least 2 and --truncation-head must be at least 0 and below 1")
+		os.Exit(1)
+	}
+
+	if _, err := tui.DefaultKeyMap().WithOverrides(opts.KeyBindings); err != nil {
+		fmt.Println("Error in key bindings:", err)
+		os.Exit(1)
+	}
+
+	// Load custom package categories
+	if opts.CategoriesPath != "" {
+		opts.CustomCategories, err = config.LoadCategories(opts.CategoriesPath)
+		if err != nil {
+			fmt.Println("Error loading categories:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *listMode {
+		os.Exit(listPackages(config.MergeCategories(config.PackageCategories, opts.CustomCategories), *listJSON))
+	}
+
+	// The installer is interactive, so don't hang without a terminal to draw on
+	if !isTerminal(os.Stdout) || !isTerminal(os.Stdin) {
+		fmt.Fprintln(os.Stderr, "Error: lunaris-installer must be run in an interactive terminal")
+		os.Exit(1)
+	}
+
+	// Create a new model, watched so Ctrl+C still quits if it gets stuck
+	monitor := &loopMonitor{}
+	m := monitoredModel{Model: tui.NewModelWithOptions(opts), monitor: monitor}
 
 	// Initialize the program
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	var programOpts []tea.ProgramOption
+	if !opts.NoAltScreen {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(m, programOpts...)
+
+	// The model quits on a second Ctrl+C, this covers an event loop that hangs
+	go forceQuitWhenStuck(monitor, terminalRestorer(!opts.NoAltScreen))
+
+	// Stop when the terminal is closed, bubbletea quits on SIGINT and SIGTERM itself
+	go killOnHangup(p)
 
 	// Run the program
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+
+	// Don't leave a build running after quitting in the middle of one
+	aur.Cleanup()
+
+	if errors.Is(err, tea.ErrProgramKilled) {
+		fmt.Fprintln(os.Stderr, "Terminal closed, exiting")
+		os.Exit(128 + int(syscall.SIGHUP))
+	}
+	if err != nil {
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
 	}
+
+	// Report a stalled installation once the terminal is restored, for unattended runs
+	if stalled, ok := final.(monitoredModel).Model.(interface{ StallError() error }); ok && stalled.StallError() != nil {
+		fmt.Fprintln(os.Stderr, "Error:", stalled.StallError())
+		os.Exit(1)
+	}
+}
+
+// isTerminal reports whether file is a terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// hangupGrace is how long the program has to stop after a hangup before the
+// installer exits without it
+const hangupGrace = 5 * time.Second
+
+// killOnHangup kills the program on SIGHUP, which bubbletea doesn't handle, so
+// Run returns and main cleans up. If the event loop is stuck, the installer
+// cleans up and exits by itself, there is no terminal left to restore.
+func killOnHangup(p *tea.Program) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	<-sig
+	p.Kill()
+
+	time.Sleep(hangupGrace)
+	aur.Cleanup()
+	os.Exit(128 + int(syscall.SIGHUP))
 }
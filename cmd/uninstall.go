@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/manifest"
+)
+
+// uninstall removes the dotfiles recorded in the manifest, asking before
+// removing anything unless assumeYes is set, and returns the exit code. When
+// backupLabel is set only the backups with that label are offered to restore.
+func uninstall(assumeYes bool, backupLabel string) int {
+	manifestPath := manifest.DefaultPath()
+	files, err := manifest.Read(manifestPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Println("No installed files recorded in", manifestPath)
+		return 0
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: failed to get home directory:", err)
+		return 1
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if !confirm(reader, fmt.Sprintf("Remove %d files installed by HyprLuna from %s?", len(files), homeDir), assumeYes) {
+		fmt.Println("Uninstall cancelled")
+		return 0
+	}
+
+	// Only offer to restore when there is a backup to restore from
+	backups, err := config.ListBackups(filepath.Join(homeDir, config.BackupDir))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+	if backupLabel != "" {
+		backups = slices.DeleteFunc(backups, func(b config.Backup) bool { return b.Label != backupLabel })
+		if len(backups) == 0 {
+			fmt.Printf("No backup labelled %s, not restoring any files\n", backupLabel)
+		}
+	}
+	backupDir := chooseBackup(reader, backups, assumeYes)
+
+	if err := manifest.Remove(homeDir, files, backupDir, func(line string) { fmt.Println(line) }); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	if err := os.Remove(manifestPath); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: failed to remove the manifest:", err)
+	}
+
+	fmt.Println("HyprLuna has been uninstalled")
+	return 0
+}
+
+// chooseBackup asks which of backups to restore and returns its directory,
+// or an empty string to restore none. With assumeYes the newest is restored.
+func chooseBackup(reader *bufio.Reader, backups []config.Backup, assumeYes bool) string {
+	if len(backups) == 0 {
+		return ""
+	}
+	if len(backups) == 1 || assumeYes {
+		if confirm(reader, fmt.Sprintf("Restore the files backed up in %s?", backups[0].Dir), assumeYes) {
+			return backups[0].Dir
+		}
+		return ""
+	}
+
+	fmt.Println("Backups:")
+	for i, backup := range backups {
+		fmt.Printf("  %d. %s\n", i+1, backup)
+	}
+	fmt.Printf("Restore which backup? [1-%d, empty for none] ", len(backups))
+	answer, _ := reader.ReadString('\n')
+	choice, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || choice < 1 || choice > len(backups) {
+		return ""
+	}
+	return backups[choice-1].Dir
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(reader *bufio.Reader, question string, assumeYes bool) bool {
+	if assumeYes {
+		return true
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,123 @@
+package aur
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"time"
+)
+
+// aurInfoURL is the AUR RPC endpoint packages missing from the sync
+// databases are looked up with
+const aurInfoURL = "https://aur.archlinux.org/rpc/v5/info"
+
+// aurInfoBatch is how many packages are looked up per AUR request, keeping
+// the URL short
+const aurInfoBatch = 100
+
+// targetNotFoundPattern matches the pacman error for a package that isn't in
+// the sync databases
+var targetNotFoundPattern = regexp.MustCompile(`target not found: (\S+)`)
+
+// CheckAvailability returns the packages that are neither in the sync
+// databases nor in the AUR. Packages are resolved by pacman first, so groups
+// and virtual packages count as available; the rest are looked up in the AUR
+// in batches.
+func CheckAvailability(packages []string) ([]string, error) {
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	notInRepos, err := missingFromRepos(packages)
+	if err != nil {
+		return nil, err
+	}
+	if len(notInRepos) == 0 {
+		return nil, nil
+	}
+
+	inAUR, err := aurPackages(notInRepos)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, pkg := range notInRepos {
+		if !inAUR[pkg] {
+			missing = append(missing, pkg)
+		}
+	}
+	return missing, nil
+}
+
+// AllInOfficialRepos reports whether pacman finds every package in the sync
+// databases, so they can be installed without an AUR helper
+func AllInOfficialRepos(packages []string) (bool, error) {
+	if len(packages) == 0 {
+		return true, nil
+	}
+
+	notInRepos, err := missingFromRepos(packages)
+	if err != nil {
+		return false, err
+	}
+	return len(notInRepos) == 0, nil
+}
+
+// missingFromRepos returns the packages pacman can't find in the sync databases
+func missingFromRepos(packages []string) ([]string, error) {
+	args := append([]string{"-Sp", "--print-format", "%n", "--"}, packages...)
+	var stderr bytes.Buffer
+	cmd := exec.Command("pacman", args...)
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	// pacman reports every target it can't find before failing
+	var missing []string
+	for _, match := range targetNotFoundPattern.FindAllStringSubmatch(stderr.String(), -1) {
+		missing = append(missing, match[1])
+	}
+	if runErr != nil && len(missing) == 0 {
+		return nil, fmt.Errorf("failed to look up packages with pacman: %w", runErr)
+	}
+	return missing, nil
+}
+
+// aurPackages returns which of packages exist in the AUR
+func aurPackages(packages []string) (map[string]bool, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	found := make(map[string]bool)
+
+	for start := 0; start < len(packages); start += aurInfoBatch {
+		query := url.Values{"arg[]": packages[start:min(start+aurInfoBatch, len(packages))]}
+		resp, err := client.Get(aurInfoURL + "?" + query.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the AUR: %w", err)
+		}
+
+		var info struct {
+			Type    string `json:"type"`
+			Error   string `json:"error"`
+			Results []struct {
+				Name string `json:"Name"`
+			} `json:"results"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&info)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the AUR response: %w", err)
+		}
+		if info.Type == "error" {
+			return nil, fmt.Errorf("AUR query failed: %s", info.Error)
+		}
+
+		for _, result := range info.Results {
+			found[result.Name] = true
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,115 @@
+package aur
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/system"
+)
+
+// Default build settings, conservative to keep low-end machines responsive
+const (
+	DefaultNice = 19
+
+	// Building the AUR helper itself is a single build, so it can use more jobs
+	// than package builds, which may run alongside pacman
+	defaultHelperMakeJobs  = 2
+	defaultPackageMakeJobs = 1
+)
+
+// BuildOptions controls the resources used when building packages
+type BuildOptions struct {
+	// MakeJobs is the number of parallel make and cargo jobs; 0 uses the defaults
+	MakeJobs int
+
+	// Nice is the niceness build commands run with
+	Nice int
+
+	// NoIONice runs build commands without the idle I/O scheduling class
+	NoIONice bool
+}
+
+// DefaultBuildOptions returns the default build options
+func DefaultBuildOptions() BuildOptions {
+	return BuildOptions{Nice: DefaultNice}
+}
+
+// SetBuildOptions sets the resources used when building packages
+func (h *Helper) SetBuildOptions(build BuildOptions) {
+	h.build = build
+}
+
+// niceCommand returns a command running name with the configured CPU and I/O priority
+func (h *Helper) niceCommand(name string, args ...string) *exec.Cmd {
+	niceArgs := append([]string{"nice", "-n", strconv.Itoa(h.build.Nice), name}, args...)
+	if h.build.NoIONice {
+		return exec.Command(niceArgs[0], niceArgs[1:]...)
+	}
+	return exec.Command("ionice", append([]string{"-c", "3"}, niceArgs...)...)
+}
+
+// buildEnv returns the environment for build commands, using defaultJobs
+// parallel jobs unless MakeJobs is set
+func (h *Helper) buildEnv(defaultJobs int) []string {
+	jobs := defaultJobs
+	if h.build.MakeJobs > 0 {
+		jobs = h.build.MakeJobs
+	}
+
+	return append(os.Environ(),
+		fmt.Sprintf("MAKEFLAGS=-j%d", jobs),      // Limit the number of make jobs
+		fmt.Sprintf("CARGO_BUILD_JOBS=%d", jobs), // Limit the number of Rust build jobs
+		"RUSTFLAGS=-Ccodegen-units=1",            // Reduce Rust memory usage
+	)
+}
+
+// Retries of the AUR helper build after a transient failure
+const (
+	aurBuildAttempts   = 3
+	aurBuildRetryDelay = 5 * time.Second
+)
+
+// transientPatterns appear in git, curl and makepkg output when a download
+// failed in a way that may succeed if tried again
+var transientPatterns = []string{
+	"Could not resolve host",
+	"Temporary failure in name resolution",
+	"Operation timed out",
+	"Connection timed out",
+	"Connection reset",
+	"Failed to connect",
+	"Failure while downloading",
+	"failed retrieving file",
+	"failed to retrieve some files",
+	"unable to access",
+}
+
+// isTransientLine reports whether a line of output describes a transient download failure
+func isTransientLine(line string) bool {
+	for _, pattern := range transientPatterns {
+		if strings.Contains(line, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientBuildFailure reports whether a failed build is worth retrying.
+// Missing networking is left to the user to fix, and so are failures with
+// no sign of a download problem, such as a broken PKGBUILD.
+func isTransientBuildFailure(err error, output []string) bool {
+	if err == nil || errors.Is(err, system.ErrNoNetwork) {
+		return false
+	}
+	for _, line := range output {
+		if isTransientLine(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package aur
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// SortByDependencies orders packages so that each one comes after the
+// packages it depends on, keeping the original order otherwise. Dependencies
+// are read from the sync databases with pacman -Si, so AUR packages keep
+// their place. The original order is returned if no dependencies can be read.
+func SortByDependencies(packages []string) []string {
+	// Without targets pacman -Si prints every package in the repositories
+	if len(packages) < 2 {
+		return packages
+	}
+
+	args := append([]string{"-Si", "--"}, packages...)
+	cmd := exec.Command("pacman", args...)
+	// The field names are translated in other locales
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+
+	// pacman fails when any package isn't in the repositories but still
+	// prints the others, so the exit status is ignored
+	output, _ := cmd.Output()
+
+	depends := parseDependencies(output)
+	if len(depends) == 0 {
+		return packages
+	}
+	return sortByDependencies(packages, depends)
+}
+
+// parseDependencies returns the "Depends On" field of each package in pacman -Si output
+func parseDependencies(output []byte) map[string][]string {
+	depends := make(map[string][]string)
+
+	var name, field string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Long fields are wrapped onto indented lines
+		value := line
+		if key, rest, ok := strings.Cut(line, " : "); ok && !strings.HasPrefix(line, " ") {
+			field = strings.TrimSpace(key)
+			value = rest
+		}
+
+		switch field {
+		case "Name":
+			name = strings.TrimSpace(value)
+		case "Depends On":
+			for _, dep := range strings.Fields(value) {
+				if dep == "None" {
+					continue
+				}
+				// Drop version constraints such as glibc>=2.38
+				if i := strings.IndexAny(dep, "<>="); i >= 0 {
+					dep = dep[:i]
+				}
+				depends[name] = append(depends[name], dep)
+			}
+		}
+	}
+	return depends
+}
+
+// sortByDependencies topologically sorts packages by depends, preferring the
+// original order among packages that are ready. Packages in a dependency
+// cycle are appended in their original order.
+func sortByDependencies(packages []string, depends map[string][]string) []string {
+	queued := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		queued[pkg] = true
+	}
+
+	sorted := make([]string, 0, len(packages))
+	done := make(map[string]bool, len(packages))
+	ready := func(pkg string) bool {
+		for _, dep := range depends[pkg] {
+			if queued[dep] && !done[dep] && dep != pkg {
+				return false
+			}
+		}
+		return true
+	}
+
+	pending := append([]string(nil), packages...)
+	for len(pending) > 0 {
+		next := slices.IndexFunc(pending, ready)
+		if next < 0 {
+			return append(sorted, pending...)
+		}
+
+		sorted = append(sorted, pending[next])
+		done[pending[next]] = true
+		pending = slices.Delete(pending, next, next+1)
+	}
+	return sorted
+}
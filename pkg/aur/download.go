@@ -0,0 +1,155 @@
+package aur
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// progressColumns is the terminal width pacman is told it has, so it draws
+// its progress bars even though its output is a pipe
+const progressColumns = "160"
+
+// ansiPattern matches the escape sequences pacman moves the cursor with
+// while redrawing several progress bars
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// progressBarPattern matches the bar and percentage pacman ends its progress
+// lines with, e.g. "(1/3) installing foo   [##########--------]  55%"
+var progressBarPattern = regexp.MustCompile(`\s*\[[^\]]*\]\s+(\d+)%\s*$`)
+
+// fileProgressPattern matches the progress line of a single download, e.g.
+// " hyprland-0.45.2-1-x86_64   12.5 MiB  6.20 MiB/s 00:01 [#####-----]  50%"
+var fileProgressPattern = regexp.MustCompile(`^\s*(\S+)\s+([\d.]+)\s+(B|KiB|MiB|GiB)\s+[\d.]+\s+(?:B|KiB|MiB|GiB)/s\s+\S+\s+\[[^\]]*\]\s+(\d+)%\s*$`)
+
+// sizeUnits are the multipliers of the size units pacman prints
+var sizeUnits = map[string]float64{
+	"B":   1,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+}
+
+// downloadTracker aggregates pacman download sizes across transactions
+type downloadTracker struct {
+	mu      sync.Mutex
+	total   uint64
+	done    uint64            // Bytes of the finished transactions
+	pending uint64            // Bytes announced by the running transaction
+	files   map[string]uint64 // Bytes downloaded of each file of the running transaction
+}
+
+// track updates the download totals from a line of package manager output
+// and reports whether it was the progress line of a download, which is only
+// worth tracking, not showing
+func (t *downloadTracker) track(line string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// A new transaction announces its download size up front
+	if size, ok := ParseDownloadSize(line); ok {
+		t.total += size
+		t.pending += size
+		return false
+	}
+
+	// Each file reports how much of it has been downloaded while it downloads
+	if match := fileProgressPattern.FindStringSubmatch(line); match != nil {
+		if size, ok := parseSize(match[2], match[3]); ok {
+			if t.files == nil {
+				t.files = make(map[string]uint64)
+			}
+			t.files[match[1]] = size
+		}
+		return true
+	}
+
+	// pacman verifies packages once every file of the transaction is downloaded
+	if t.pending > 0 && (strings.Contains(line, "checking keys") || strings.Contains(line, "checking package integrity")) {
+		t.finish()
+	}
+	return false
+}
+
+// progress returns the downloaded and total bytes
+func (t *downloadTracker) progress() (uint64, uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Sizes are rounded to a tenth of a unit, so don't exceed the announcement
+	var running uint64
+	for _, size := range t.files {
+		running += size
+	}
+	return t.done + min(running, t.pending), t.total
+}
+
+// complete counts every announced download as done
+func (t *downloadTracker) complete() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finish()
+}
+
+// finish counts the running transaction as downloaded, t.mu must be held
+func (t *downloadTracker) finish() {
+	t.done += t.pending
+	t.pending = 0
+	t.files = nil
+}
+
+// stripEscapes removes the cursor movements from a line of pacman output
+func stripEscapes(line string) string {
+	return ansiPattern.ReplaceAllString(line, "")
+}
+
+// progressText removes the bar from the end of a progress line. ok is false
+// for progress lines that aren't at 100% yet, which are redrawn until they are.
+func progressText(line string) (string, bool) {
+	match := progressBarPattern.FindStringSubmatchIndex(line)
+	if match == nil {
+		return line, true
+	}
+	if line[match[2]:match[3]] != "100" {
+		return "", false
+	}
+	return strings.TrimSpace(line[:match[0]]), true
+}
+
+// ParseDownloadSize parses pacman's "Total Download Size:   523.40 MiB" line into bytes
+func ParseDownloadSize(line string) (uint64, bool) {
+	_, value, ok := strings.Cut(line, "Total Download Size:")
+	if !ok {
+		return 0, false
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return 0, false
+	}
+
+	return parseSize(fields[0], fields[1])
+}
+
+// parseSize parses a size pacman printed as an amount and a unit, e.g. "12.5" and "MiB"
+func parseSize(amount, unit string) (uint64, bool) {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, false
+	}
+
+	return uint64(value * multiplier), true
+}
+
+// DownloadProgress returns the bytes downloaded and the total bytes announced
+// so far across all installs. It is safe to call while packages are being
+// installed, to show the progress of the running download.
+func (h *Helper) DownloadProgress() (uint64, uint64) {
+	return h.downloads.progress()
+}
@@ -0,0 +1,107 @@
+package aur
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Errors of the package manager commands, wrapped by the errors Install,
+// InstallPackages and DownloadPackages return so they can be told apart with
+// errors.Is instead of by their message
+var (
+	// ErrConflict is wrapped by ConflictError
+	ErrConflict = errors.New("package conflict detected")
+
+	// ErrSudoRequired is returned when sudo needed a password it didn't get
+	// or rejected the one it got
+	ErrSudoRequired = errors.New("sudo password required")
+
+	// ErrPackageNotFound is returned when a package is neither in the sync
+	// databases nor, for an AUR helper, in the AUR
+	ErrPackageNotFound = errors.New("package not found")
+
+	// ErrTimeout is returned when a command ran longer than the helper's
+	// timeout, once it has been killed and has exited
+	ErrTimeout = errors.New("command timed out")
+)
+
+// ConflictError is returned when the package manager reports a conflict
+// with an installed package
+type ConflictError struct {
+	Line string // Output line reporting the conflict
+}
+
+// Error returns the conflict as reported by the package manager
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error() + ": " + e.Line
+}
+
+// Unwrap returns ErrConflict
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// sudoFailurePatterns are output lines of sudo failing for a missing or wrong password
+var sudoFailurePatterns = []string{
+	"a password is required",
+	"incorrect password attempt",
+	"Sorry, try again",
+	"no tty present and no askpass program specified",
+}
+
+// notFoundPatterns are output lines of pacman and the AUR helpers failing to find a package
+var notFoundPatterns = []string{
+	"target not found",
+	"No AUR package found for",
+	"could not find all required packages",
+}
+
+// failureTracker picks the cause of a failed command from its output
+type failureTracker struct {
+	mu    sync.Mutex
+	cause error
+}
+
+// track records the cause of the failure line reports, if any
+func (t *failureTracker) track(line string) {
+	var cause error
+	switch {
+	case containsAny(line, sudoFailurePatterns):
+		cause = ErrSudoRequired
+	case containsAny(line, notFoundPatterns):
+		cause = ErrPackageNotFound
+	default:
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// sudo failing hides every other problem
+	if t.cause == nil || cause == ErrSudoRequired {
+		t.cause = cause
+	}
+}
+
+// wrap returns err wrapping the recorded cause too, if there is one
+func (t *failureTracker) wrap(err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cause == nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", t.cause, err)
+}
+
+// containsAny reports whether line contains one of patterns
+func containsAny(line string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(line, pattern) {
+			return true
+		}
+	}
+	return false
+}
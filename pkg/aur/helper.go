@@ -7,46 +7,165 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/system"
 )
 
+// The running package manager and AUR helper build, which the exit and stall
+// handlers read from other goroutines, so they are guarded by processMu
 var (
+	processMu  sync.Mutex
 	currentCmd *exec.Cmd
 	stdinPipe  io.WriteCloser
+	buildDir   string // Temporary directory the AUR helper is being built in
 )
 
+// lastOutput is when a package manager last printed a line, in Unix nanoseconds
+var lastOutput atomic.Int64
+
+// noteOutput records that a package manager printed a line
+func noteOutput() {
+	lastOutput.Store(time.Now().UnixNano())
+}
+
+// LastOutput returns when a package manager, the AUR helper build included,
+// last printed a line, or the zero time if none has
+func LastOutput() time.Time {
+	last := lastOutput.Load()
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
+
 // GetCurrentPackageManager returns the current package manager command
 func GetCurrentPackageManager() *exec.Cmd {
+	processMu.Lock()
+	defer processMu.Unlock()
 	return currentCmd
 }
 
+// setPackageManager tracks a started package manager process
+func setPackageManager(cmd *exec.Cmd, stdin io.WriteCloser) {
+	processMu.Lock()
+	defer processMu.Unlock()
+	currentCmd = cmd
+	stdinPipe = stdin
+}
+
+// setBuildDir tracks the directory the AUR helper is being built in
+func setBuildDir(dir string) {
+	processMu.Lock()
+	defer processMu.Unlock()
+	buildDir = dir
+}
+
+// PacmanOnly is the helper name used when no AUR helper should be installed
+const PacmanOnly = "none"
+
 // Helper represents an AUR helper
 type Helper struct {
 	Name         string
 	Command      string
 	sudoPassword string
+	timeout      time.Duration
+	reinstall    bool // Install packages that are already up to date again
+	build        BuildOptions
+	downloads    downloadTracker
+
+	// Full path of Command, found once the helper is installed
+	resolvedPath string
 }
 
 // NewHelper creates a new AUR helper
 func NewHelper(name string) *Helper {
+	// Without an AUR helper, packages are installed with pacman directly
+	if name == PacmanOnly {
+		return &Helper{
+			Name:    name,
+			Command: "pacman",
+			timeout: 30 * time.Minute,
+			build:   DefaultBuildOptions(),
+		}
+	}
+
 	return &Helper{
 		Name:    name,
 		Command: name,
+		timeout: 30 * time.Minute,
+		build:   DefaultBuildOptions(),
 	}
 }
 
+// String returns the name of the helper
+func (h *Helper) String() string {
+	return h.Name
+}
+
+// IsPacmanOnly checks if the helper installs packages with pacman instead of an AUR helper
+func (h *Helper) IsPacmanOnly() bool {
+	return h.Name == PacmanOnly
+}
+
+// helperDirs are the directories AUR helpers are installed to, checked when
+// the installer's PATH doesn't include them
+var helperDirs = []string{"/usr/bin", "/usr/local/bin"}
+
 // IsInstalled checks if the AUR helper is installed
 func (h *Helper) IsInstalled() bool {
-	_, err := exec.LookPath(h.Command)
-	return err == nil
+	_, ok := h.lookPath()
+	return ok
+}
+
+// lookPath finds the helper's command in PATH, then in the install locations
+func (h *Helper) lookPath() (string, bool) {
+	if path, err := exec.LookPath(h.Command); err == nil {
+		return path, true
+	}
+
+	for _, dir := range helperDirs {
+		path := filepath.Join(dir, filepath.Base(h.Command))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// inPath reports whether dir is one of the directories in PATH
+func inPath(dir string) bool {
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(entry) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// executable returns the path to run the helper with, which is its full path
+// once it's installed, so it runs even when PATH doesn't include it
+func (h *Helper) executable() string {
+	if h.resolvedPath != "" {
+		return h.resolvedPath
+	}
+	return h.Command
 }
 
 // Install installs the AUR helper
 func (h *Helper) Install() ([]string, error) {
+	// Nothing to build when using pacman only
+	if h.IsPacmanOnly() {
+		return []string{"No AUR helper selected, using pacman"}, nil
+	}
+
 	// If the helper is already installed, return nil
-	if h.IsInstalled() {
+	if path, ok := h.lookPath(); ok {
+		h.resolvedPath = path
 		return []string{"AUR helper already installed"}, nil
 	}
 
@@ -86,6 +205,9 @@ func (h *Helper) Install() ([]string, error) {
 		return messages, fmt.Errorf("failed to start base-devel installation: %w", err)
 	}
 
+	// Track pacman so a stalled installation can kill it
+	setPackageManager(baseDevelCmd, nil)
+
 	// If we have a sudo password, send it
 	if h.sudoPassword != "" {
 		fmt.Fprintf(baseDevelStdin, "%s\n", h.sudoPassword)
@@ -93,13 +215,17 @@ func (h *Helper) Install() ([]string, error) {
 	baseDevelStdin.Close()
 
 	// Read output line by line to avoid storing everything in memory
+	var failures failureTracker
 	baseDevelDone := make(chan struct{})
 	go func() {
 		defer close(baseDevelDone)
 		scanner := bufio.NewScanner(io.MultiReader(baseDevelStdout, baseDevelStderr))
 		for scanner.Scan() {
 			line := scanner.Text()
+			noteOutput()
 			if line != "" {
+				failures.track(line)
+
 				// Only keep important messages
 				if strings.Contains(line, "error") || strings.Contains(line, "warning") ||
 					strings.Contains(line, "installing") || strings.Contains(line, "upgraded") ||
@@ -112,20 +238,59 @@ func (h *Helper) Install() ([]string, error) {
 	}()
 
 	// Wait for the command to complete
-	if err := baseDevelCmd.Wait(); err != nil {
+	err = baseDevelCmd.Wait()
+	ClearPackageManager()
+	if err != nil {
 		<-baseDevelDone // Ensure goroutine is done
-		return messages, fmt.Errorf("failed to install base-devel: %w", err)
+		return messages, failures.wrap(fmt.Errorf("failed to install base-devel: %w", err))
 	}
 	<-baseDevelDone // Ensure goroutine is done
 
 	messages = append(messages, "base-devel installed successfully")
 
+	// Retry the build when it fails because of the network, e.g. a mirror timing out
+	var buildErr error
+	for attempt := 1; attempt <= aurBuildAttempts; attempt++ {
+		if attempt > 1 {
+			delay := time.Duration(attempt-1) * aurBuildRetryDelay
+			messages = append(messages, fmt.Sprintf("Warning: download problem while building %s, retrying in %s (attempt %d of %d)", h.Name, delay, attempt, aurBuildAttempts))
+			time.Sleep(delay)
+		}
+
+		var buildMessages []string
+		buildMessages, buildErr = h.buildFromAUR()
+		messages = append(messages, buildMessages...)
+		if buildErr == nil {
+			// makepkg succeeding doesn't mean the command can be run
+			path, ok := h.lookPath()
+			if !ok {
+				return messages, fmt.Errorf("%s was built but the %s command was not found in PATH or %s", h.Name, h.Command, strings.Join(helperDirs, ", "))
+			}
+			h.resolvedPath = path
+			return messages, nil
+		}
+		if !isTransientBuildFailure(buildErr, buildMessages) {
+			return messages, buildErr
+		}
+	}
+	return messages, buildErr
+}
+
+// buildFromAUR clones the AUR helper into a fresh temporary directory and
+// builds and installs it with makepkg
+func (h *Helper) buildFromAUR() ([]string, error) {
+	messages := make([]string, 0, 20)
+
 	// Create a temporary directory
 	tempDir, err := os.MkdirTemp("", "aur-helper")
 	if err != nil {
 		return messages, fmt.Errorf("failed to create temporary directory: %w", err)
 	}
-	defer os.RemoveAll(tempDir)
+	setBuildDir(tempDir)
+	defer func() {
+		os.RemoveAll(tempDir)
+		setBuildDir("")
+	}()
 
 	// Change to the temporary directory
 	originalDir, err := os.Getwd()
@@ -137,10 +302,21 @@ func (h *Helper) Install() ([]string, error) {
 	}
 	defer os.Chdir(originalDir)
 
+	// Make sure git is available before cloning
+	if err := system.RequireGit(); err != nil {
+		return messages, err
+	}
+
+	// Fail fast instead of waiting for git to time out without a network
+	if err := system.CheckNetwork("aur.archlinux.org:443"); err != nil {
+		return messages, err
+	}
+
 	messages = append(messages, fmt.Sprintf("Cloning %s repository...", h.Name))
 
 	// Clone the AUR helper repository with depth=1 to reduce download size and memory usage
-	cloneCmd := exec.Command("git", "clone", "--depth=1", fmt.Sprintf("https://aur.archlinux.org/%s.git", h.Name))
+	// --progress makes git report progress even though its output is a pipe
+	cloneCmd := exec.Command("git", "clone", "--progress", "--depth=1", fmt.Sprintf("https://aur.archlinux.org/%s.git", h.Name))
 
 	// Use pipes instead of buffers to reduce memory usage
 	cloneStdout, err := cloneCmd.StdoutPipe()
@@ -162,8 +338,13 @@ func (h *Helper) Install() ([]string, error) {
 	go func() {
 		defer close(cloneDone)
 		scanner := bufio.NewScanner(io.MultiReader(cloneStdout, cloneStderr))
+		scanner.Split(system.ScanProgressLines)
 		for scanner.Scan() {
 			line := scanner.Text()
+			// Progress is shown as a bar instead of in the output
+			if system.TrackCloneProgress(line) {
+				continue
+			}
 			if line != "" {
 				// Only keep important messages
 				if strings.Contains(line, "error") || strings.Contains(line, "warning") ||
@@ -175,12 +356,13 @@ func (h *Helper) Install() ([]string, error) {
 		}
 	}()
 
-	// Wait for the command to complete
-	if err := cloneCmd.Wait(); err != nil {
-		<-cloneDone // Ensure goroutine is done
+	// Wait for the output to be read before waiting for the command, which closes the pipes
+	<-cloneDone
+	err = cloneCmd.Wait()
+	system.EndCloneProgress()
+	if err != nil {
 		return messages, fmt.Errorf("failed to clone repository: %w", err)
 	}
-	<-cloneDone // Ensure goroutine is done
 
 	messages = append(messages, fmt.Sprintf("Repository cloned successfully"))
 
@@ -194,17 +376,13 @@ func (h *Helper) Install() ([]string, error) {
 	// Use ionice along with nice to reduce both CPU and I/O priority
 	var cmd *exec.Cmd
 	if h.sudoPassword != "" {
-		cmd = exec.Command("ionice", "-c", "3", "nice", "-n", "19", "sudo", "-S", "makepkg", "-si", "--noconfirm", "--noprogressbar")
+		cmd = h.niceCommand("sudo", "-S", "makepkg", "-si", "--noconfirm", "--noprogressbar")
 	} else {
-		cmd = exec.Command("ionice", "-c", "3", "nice", "-n", "19", "makepkg", "-si", "--noconfirm", "--noprogressbar")
+		cmd = h.niceCommand("makepkg", "-si", "--noconfirm", "--noprogressbar")
 	}
 
 	// Set resource limits using ulimit-like environment variables if possible
-	cmd.Env = append(os.Environ(),
-		"MAKEFLAGS=-j2",               // Limit make to 2 jobs
-		"CARGO_BUILD_JOBS=2",          // Limit Rust builds to 2 jobs
-		"RUSTFLAGS=-Ccodegen-units=1", // Reduce Rust memory usage
-	)
+	cmd.Env = h.buildEnv(defaultHelperMakeJobs)
 
 	// Use pipes instead of buffers to reduce memory usage
 	stdout, err := cmd.StdoutPipe()
@@ -225,6 +403,10 @@ func (h *Helper) Install() ([]string, error) {
 		return messages, fmt.Errorf("failed to start makepkg: %w", err)
 	}
 
+	// Track makepkg like the package manager so it can be killed on exit
+	setPackageManager(cmd, nil)
+	defer ClearPackageManager()
+
 	// Send the password if we have one
 	if h.sudoPassword != "" {
 		io.WriteString(stdin, h.sudoPassword+"\n")
@@ -240,6 +422,7 @@ func (h *Helper) Install() ([]string, error) {
 	}()
 
 	// Create a channel for output processing
+	var failures failureTracker
 	outputDone := make(chan struct{})
 
 	// Read output line by line to avoid storing everything in memory
@@ -260,13 +443,17 @@ func (h *Helper) Install() ([]string, error) {
 					}
 					break
 				}
+				noteOutput()
 
 				line = strings.TrimSpace(line)
 				if line != "" {
+					failures.track(line)
+
 					// Only keep important messages
 					if strings.Contains(line, "error") || strings.Contains(line, "warning") ||
 						strings.Contains(line, "installing") || strings.Contains(line, "making") ||
-						strings.Contains(line, "building") || strings.Contains(line, "conflict") {
+						strings.Contains(line, "building") || strings.Contains(line, "conflict") ||
+						isTransientLine(line) {
 
 						// Add to messages with thread safety
 						messages = append(messages, line)
@@ -294,13 +481,17 @@ func (h *Helper) Install() ([]string, error) {
 				}
 				break
 			}
+			noteOutput()
 
 			line = strings.TrimSpace(line)
 			if line != "" {
+				failures.track(line)
+
 				// Only keep important messages
 				if strings.Contains(line, "error") || strings.Contains(line, "warning") ||
 					strings.Contains(line, "installing") || strings.Contains(line, "making") ||
-					strings.Contains(line, "building") || strings.Contains(line, "conflict") {
+					strings.Contains(line, "building") || strings.Contains(line, "conflict") ||
+					isTransientLine(line) {
 
 					// Add to messages with thread safety
 					messages = append(messages, line)
@@ -328,23 +519,25 @@ func (h *Helper) Install() ([]string, error) {
 		// Command completed
 		if err != nil {
 			messages = append(messages, fmt.Sprintf("Error: %s", err.Error()))
-			return messages, fmt.Errorf("failed to build and install package: %w", err)
+			return messages, failures.wrap(fmt.Errorf("failed to build and install package: %w", err))
 		}
 
 		messages = append(messages, fmt.Sprintf("%s installed successfully", h.Name))
 		return messages, nil
 
-	case <-time.After(30 * time.Minute): // Timeout after 30 minutes
-		// Command timed out, kill it
+	case <-time.After(h.timeout):
+		// Command timed out, kill it and wait for it to exit, so it can
+		// be run again without the two getting in each other's way
 		if cmd.Process != nil {
 			cmd.Process.Kill()
 		}
+		<-resultCh
 
 		// Wait for output processing to complete
 		<-outputDone
 
-		messages = append(messages, "Command timed out after 30 minutes")
-		return messages, fmt.Errorf("command timed out after 30 minutes")
+		messages = append(messages, fmt.Sprintf("Command timed out after %s", h.timeout))
+		return messages, fmt.Errorf("%w after %s", ErrTimeout, h.timeout)
 	}
 }
 
@@ -362,8 +555,10 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 	ClearPackageManager()
 
 	// Kill any potentially hanging processes from previous attempts
-	pkillCmd := exec.Command("pkill", "-9", h.Command)
-	pkillCmd.Run()
+	if !h.IsPacmanOnly() {
+		pkillCmd := exec.Command("pkill", "-9", h.Command)
+		pkillCmd.Run()
+	}
 	pkillPacmanCmd := exec.Command("pkill", "-9", "pacman")
 	pkillPacmanCmd.Run()
 
@@ -371,30 +566,91 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 	time.Sleep(500 * time.Millisecond)
 
 	// Build the command arguments
-	args := []string{"-S", "--needed", "--noconfirm", "--noprogressbar"}
+	args := append([]string{"-S"}, h.syncFlags()...)
 	args = append(args, packages...)
 
+	messages, err := h.runPackageManager(h.executable(), args, messages)
+	if err != nil {
+		return messages, err
+	}
+
+	messages = append(messages, "Packages installed successfully")
+	return messages, nil
+}
+
+// DownloadPackages downloads the official repository packages among packages
+// into the pacman cache without installing them. AUR packages are skipped,
+// their sources are downloaded when they are built.
+func (h *Helper) DownloadPackages(packages []string) ([]string, error) {
+	var repoPackages, aurPackages []string
+	for _, pkg := range packages {
+		if IsInOfficialRepos(pkg) {
+			repoPackages = append(repoPackages, pkg)
+		} else {
+			aurPackages = append(aurPackages, pkg)
+		}
+	}
+
+	messages := make([]string, 0, 20)
+	if len(aurPackages) > 0 {
+		messages = append(messages, fmt.Sprintf("Not downloading %d AUR packages, their sources are downloaded when they are built: %s", len(aurPackages), strings.Join(aurPackages, ", ")))
+	}
+
+	if len(repoPackages) == 0 {
+		return append(messages, "No packages to download"), nil
+	}
+
+	messages = append(messages, fmt.Sprintf("Downloading %d packages", len(repoPackages)))
+
+	// Make sure any previous package manager process is cleared
+	ClearPackageManager()
+
+	args := append([]string{"-Sw"}, h.syncFlags()...)
+	args = append(args, repoPackages...)
+
+	messages, err := h.runPackageManager("pacman", args, messages)
+	if err != nil {
+		return messages, err
+	}
+
+	// Everything announced has been downloaded, even if pacman didn't verify it yet
+	h.downloads.complete()
+
+	messages = append(messages, "Packages downloaded successfully")
+	return messages, nil
+}
+
+// runPackageManager runs command with args through sudo when needed, reading
+// its output until it exits, reports a conflict or times out
+func (h *Helper) runPackageManager(command string, args []string, messages []string) ([]string, error) {
 	// Create a command that uses sudo directly if needed
 	var cmd *exec.Cmd
 
+	// Give pacman a terminal width with env, which runs after sudo has reset
+	// the environment, so it draws the download progress of each file
+	envArgs := append([]string{"COLUMNS=" + progressColumns, command}, args...)
+
 	// Use ionice along with nice to reduce both CPU and I/O priority
 	if h.sudoPassword != "" {
-		cmd = exec.Command("ionice", "-c", "3", "nice", "-n", "19", "sudo", "-S", h.Command)
-		cmd.Args = append(cmd.Args, args...)
+		cmd = h.niceCommand("sudo", append([]string{"-S", "env"}, envArgs...)...)
 		messages = append(messages, "Using sudo with password")
+	} else if command == "pacman" {
+		// pacman always needs root, so let sudo prompt for a password itself
+		cmd = h.niceCommand("sudo", append([]string{"env"}, envArgs...)...)
+		messages = append(messages, "No password provided")
 	} else {
 		// No password provided, just use the AUR helper directly with nice
-		cmd = exec.Command("ionice", "-c", "3", "nice", "-n", "19", h.Command)
-		cmd.Args = append(cmd.Args, args...)
+		cmd = h.niceCommand("env", envArgs...)
 		messages = append(messages, "No password provided")
 	}
 
-	// Set resource limits using environment variables
-	cmd.Env = append(os.Environ(),
-		"MAKEFLAGS=-j1",               // Limit make to 1 job to reduce memory usage
-		"CARGO_BUILD_JOBS=1",          // Limit Rust builds to 1 job
-		"RUSTFLAGS=-Ccodegen-units=1", // Reduce Rust memory usage
-	)
+	// Set resource limits using environment variables, one job by default to reduce memory usage
+	cmd.Env = h.buildEnv(defaultPackageMakeJobs)
+
+	// A helper found outside PATH runs the tools installed next to it
+	if dir := filepath.Dir(command); filepath.IsAbs(command) && !inPath(dir) {
+		cmd.Env = append(cmd.Env, "PATH="+os.Getenv("PATH")+string(os.PathListSeparator)+dir)
+	}
 
 	// Set up pipes for stdin, stdout, and stderr
 	stdin, err := cmd.StdinPipe()
@@ -419,8 +675,7 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 
 	// Set the global variables to track the current package manager process
 	// We set these AFTER successfully starting the command
-	currentCmd = cmd
-	stdinPipe = stdin
+	setPackageManager(cmd, stdin)
 
 	// If we have a sudo password and we're using sudo -S, send it
 	if h.sudoPassword != "" {
@@ -438,6 +693,7 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 
 	// Create a channel for conflict detection
 	conflictCh := make(chan string, 1)
+	var failures failureTracker
 
 	// Create a channel to signal output processing is done
 	outputDone := make(chan struct{})
@@ -462,13 +718,27 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 			defer close(stdoutDone)
 			scanner := bufio.NewScanner(stdout)
 			scanner.Buffer(make([]byte, 4096), 4096) // Use a small buffer
+			scanner.Split(system.ScanProgressLines)  // Progress bars are redrawn after carriage returns
 
 			for scanner.Scan() {
-				line := scanner.Text()
+				line := stripEscapes(scanner.Text())
+				noteOutput()
 				if line == "" {
 					continue
 				}
 
+				// Track download sizes for the aggregate progress
+				if h.downloads.track(line) {
+					continue
+				}
+
+				// Show progress lines once, when they are complete
+				line, complete := progressText(line)
+				if !complete {
+					continue
+				}
+				failures.track(line)
+
 				// Check for conflicts
 				if strings.Contains(line, "conflict") {
 					select {
@@ -514,13 +784,27 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 			defer close(stderrDone)
 			scanner := bufio.NewScanner(stderr)
 			scanner.Buffer(make([]byte, 4096), 4096) // Use a small buffer
+			scanner.Split(system.ScanProgressLines)  // Progress bars are redrawn after carriage returns
 
 			for scanner.Scan() {
-				line := scanner.Text()
+				line := stripEscapes(scanner.Text())
+				noteOutput()
 				if line == "" {
 					continue
 				}
 
+				// Track download sizes for the aggregate progress
+				if h.downloads.track(line) {
+					continue
+				}
+
+				// Show progress lines once, when they are complete
+				line, complete := progressText(line)
+				if !complete {
+					continue
+				}
+				failures.track(line)
+
 				// Check for conflicts
 				if strings.Contains(line, "conflict") {
 					select {
@@ -578,15 +862,14 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 			select {
 			case conflictMsg := <-conflictCh:
 				messages = append(messages, fmt.Sprintf("Conflict detected: %s", conflictMsg))
-				return messages, fmt.Errorf("package conflict detected: %s", conflictMsg)
+				return messages, &ConflictError{Line: conflictMsg}
 			default:
 				// No conflict, just an error
 				messages = append(messages, fmt.Sprintf("Command failed: %v", err))
-				return messages, fmt.Errorf("command failed: %w", err)
+				return messages, failures.wrap(fmt.Errorf("command failed: %w", err))
 			}
 		}
 
-		messages = append(messages, "Packages installed successfully")
 		return messages, nil
 
 	case conflictMsg := <-conflictCh:
@@ -599,34 +882,42 @@ func (h *Helper) InstallPackages(packages []string) ([]string, error) {
 		<-outputDone
 
 		messages = append(messages, fmt.Sprintf("Conflict detected: %s", conflictMsg))
-		return messages, fmt.Errorf("package conflict detected: %s", conflictMsg)
+		return messages, &ConflictError{Line: conflictMsg}
 
-	case <-time.After(30 * time.Minute): // Timeout after 30 minutes
-		// Command timed out, kill it
+	case <-time.After(h.timeout):
+		// Command timed out, kill it and wait for it to exit, so it can
+		// be run again without the two getting in each other's way
 		if cmd.Process != nil {
 			cmd.Process.Kill()
 		}
+		<-resultCh
 
 		// Wait for output processing to complete
 		<-outputDone
 
-		messages = append(messages, "Command timed out after 30 minutes")
-		return messages, fmt.Errorf("command timed out after 30 minutes")
+		messages = append(messages, fmt.Sprintf("Command timed out after %s", h.timeout))
+		return messages, fmt.Errorf("%w after %s", ErrTimeout, h.timeout)
 	}
 }
 
 // SendInputToPackageManager sends input to the current package manager process
 func SendInputToPackageManager(input string) error {
-	if stdinPipe == nil {
+	processMu.Lock()
+	stdin := stdinPipe
+	processMu.Unlock()
+
+	if stdin == nil {
 		return fmt.Errorf("no active package manager process")
 	}
 
-	_, err := fmt.Fprintf(stdinPipe, "%s\n", input)
+	_, err := fmt.Fprintf(stdin, "%s\n", input)
 	return err
 }
 
 // IsPackageManagerActive checks if a package manager process is active
 func IsPackageManagerActive() bool {
+	processMu.Lock()
+	defer processMu.Unlock()
 	return currentCmd != nil && currentCmd.Process != nil
 }
 
@@ -652,6 +943,12 @@ func GetInstalledPackages() ([]string, error) {
 	return packages, nil
 }
 
+// IsInOfficialRepos checks if a package is available from the official repositories
+func IsInOfficialRepos(pkg string) bool {
+	cmd := exec.Command("pacman", "-Si", pkg)
+	return cmd.Run() == nil
+}
+
 // IsPackageInstalled checks if a package is installed
 func IsPackageInstalled(pkg string) bool {
 	cmd := exec.Command("pacman", "-Q", pkg)
@@ -663,6 +960,43 @@ func (h *Helper) SetSudoPassword(password string) {
 	h.sudoPassword = password
 }
 
+// SetTimeout sets how long a single install command may run
+func (h *Helper) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		h.timeout = timeout
+	}
+}
+
+// SetReinstall sets whether packages that are already up to date are installed again
+func (h *Helper) SetReinstall(reinstall bool) {
+	h.reinstall = reinstall
+}
+
+// syncFlags returns the flags package installs and downloads run with.
+// Up to date packages are skipped unless reinstalling. Progress bars are
+// kept for the download progress, see progressText.
+func (h *Helper) syncFlags() []string {
+	flags := []string{"--noconfirm"}
+	if !h.reinstall {
+		flags = append([]string{"--needed"}, flags...)
+	}
+	return flags
+}
+
+// InstallCommand returns the shell command that installs packages with the
+// helper by hand, e.g. to reproduce an installation outside the installer.
+// Unlike the installer, it lets the helper ask for confirmation.
+func (h *Helper) InstallCommand(packages []string) string {
+	args := []string{h.Command, "-S"}
+	if h.IsPacmanOnly() {
+		args = append([]string{"sudo"}, args...)
+	}
+	if !h.reinstall {
+		args = append(args, "--needed")
+	}
+	return strings.Join(append(args, packages...), " ")
+}
+
 // GetSudoPassword returns the sudo password for the AUR helper
 func (h *Helper) GetSudoPassword() string {
 	return h.sudoPassword
@@ -670,6 +1004,27 @@ func (h *Helper) GetSudoPassword() string {
 
 // ClearPackageManager clears the current package manager process
 func ClearPackageManager() {
-	currentCmd = nil
-	stdinPipe = nil
+	setPackageManager(nil, nil)
+}
+
+// KillPackageManager kills the current package manager process, if any
+func KillPackageManager() {
+	processMu.Lock()
+	defer processMu.Unlock()
+	if currentCmd != nil && currentCmd.Process != nil {
+		currentCmd.Process.Kill()
+	}
+}
+
+// Cleanup kills the current package manager process and removes the AUR
+// helper build directory, for when the installer exits while they are in use
+func Cleanup() {
+	KillPackageManager()
+
+	processMu.Lock()
+	dir := buildDir
+	processMu.Unlock()
+	if dir != "" {
+		os.RemoveAll(dir)
+	}
 }
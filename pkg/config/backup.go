@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// backupTimeLayout is the timestamp backups in BackupDir are named with
+const backupTimeLayout = "20060102-150405"
+
+// maxBackupLabelLength keeps backup directory names readable
+const maxBackupLabelLength = 64
+
+// unsafeLabelPattern matches the characters not allowed in backup labels
+var unsafeLabelPattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Backup is a backup of BackupDirs taken before installing dotfiles
+type Backup struct {
+	Dir   string    // Directory the backup is stored in
+	Label string    // Label the backup was taken with, if any
+	Time  time.Time // When the backup was taken, zero for a backup in the layout of older versions
+}
+
+// String describes the backup by its label and the time it was taken
+func (b Backup) String() string {
+	switch {
+	case b.Time.IsZero():
+		return "backup taken by an older installer version"
+	case b.Label == "":
+		return b.Time.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprintf("%s (%s)", b.Label, b.Time.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// SanitizeBackupLabel makes label safe to use in a directory name: runs of
+// characters other than letters, digits, dots, dashes and underscores become
+// a dash, and leading and trailing dots and dashes are dropped so the backup
+// can neither be hidden nor refer to another directory
+func SanitizeBackupLabel(label string) string {
+	label = unsafeLabelPattern.ReplaceAllString(strings.TrimSpace(label), "-")
+	if len(label) > maxBackupLabelLength {
+		label = label[:maxBackupLabelLength]
+	}
+	return strings.Trim(label, ".-")
+}
+
+// BackupName returns the name of the BackupDir subdirectory a backup taken
+// at t is stored in: its timestamp, followed by the sanitized label if any,
+// e.g. 20240131-182500-before-hyprluna
+func BackupName(label string, t time.Time) string {
+	name := t.Format(backupTimeLayout)
+	if label = SanitizeBackupLabel(label); label != "" {
+		name += "-" + label
+	}
+	return name
+}
+
+// parseBackupName returns the time and label of a backup named by BackupName
+func parseBackupName(name string) (time.Time, string, bool) {
+	if len(name) < len(backupTimeLayout) {
+		return time.Time{}, "", false
+	}
+	t, err := time.ParseInLocation(backupTimeLayout, name[:len(backupTimeLayout)], time.Local)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	rest := name[len(backupTimeLayout):]
+	if rest == "" {
+		return t, "", true
+	}
+	if label, ok := strings.CutPrefix(rest, "-"); ok && label != "" {
+		return t, label, true
+	}
+	return time.Time{}, "", false
+}
+
+// ListBackups returns the backups in dir, the newest first. Older versions
+// backed up BackupDirs into dir itself; such a backup is listed last.
+func ListBackups(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []Backup
+	legacy := false
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if slices.Contains(BackupDirs, entry.Name()) {
+			legacy = true
+			continue
+		}
+		if t, label, ok := parseBackupName(entry.Name()); ok {
+			backups = append(backups, Backup{Dir: filepath.Join(dir, entry.Name()), Label: label, Time: t})
+		}
+	}
+
+	slices.SortStableFunc(backups, func(a, b Backup) int {
+		return b.Time.Compare(a.Time)
+	})
+	if legacy {
+		backups = append(backups, Backup{Dir: dir})
+	}
+	return backups, nil
+}
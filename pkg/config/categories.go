@@ -0,0 +1,156 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// packageNamePattern matches valid pacman package names
+var packageNamePattern = regexp.MustCompile(`^[a-z0-9@_+][a-z0-9@._+-]*$`)
+
+// ValidPackageName reports whether name is a valid pacman package name
+func ValidPackageName(name string) bool {
+	return packageNamePattern.MatchString(name)
+}
+
+// ValidatePackageName returns an error if name is not a valid pacman package name
+func ValidatePackageName(name string) error {
+	if !ValidPackageName(name) {
+		return fmt.Errorf("invalid package name %q", name)
+	}
+	return nil
+}
+
+// ValidatePackageNames returns an error naming the first invalid package name
+func ValidatePackageNames(names []string) error {
+	for _, name := range names {
+		if err := ValidatePackageName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tags returns the sorted tags used by the options of categories
+func Tags(categories []PackageCategory) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, category := range categories {
+		for _, option := range category.Options {
+			for _, tag := range option.Tags {
+				if tag != "" && !seen[tag] {
+					seen[tag] = true
+					tags = append(tags, tag)
+				}
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// LoadCategories reads additional package categories from a JSON file
+func LoadCategories(path string) ([]PackageCategory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read categories file: %w", err)
+	}
+
+	var categories []PackageCategory
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("failed to parse categories file %s: %w", path, err)
+	}
+
+	// Tolerate stray whitespace around package names and tags
+	for _, category := range categories {
+		for _, option := range category.Options {
+			for i, pkg := range option.Packages {
+				option.Packages[i] = strings.TrimSpace(pkg)
+			}
+			for i, tag := range option.Tags {
+				option.Tags[i] = strings.TrimSpace(tag)
+			}
+		}
+	}
+
+	if err := ValidateCategories(categories); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return categories, nil
+}
+
+// ValidateCategories checks that categories are well formed and only reference valid package names
+func ValidateCategories(categories []PackageCategory) error {
+	seenCategories := make(map[string]bool)
+	for i, category := range categories {
+		if category.Name == "" {
+			return fmt.Errorf("category %d has no name", i+1)
+		}
+		if category.Name == BasePackagesCategoryName {
+			return fmt.Errorf("category %q is reserved", category.Name)
+		}
+		if seenCategories[category.Name] {
+			return fmt.Errorf("duplicate category %q", category.Name)
+		}
+		seenCategories[category.Name] = true
+
+		if len(category.Options) == 0 {
+			return fmt.Errorf("category %q has no options", category.Name)
+		}
+
+		seenOptions := make(map[string]bool)
+		for j, option := range category.Options {
+			if option.Name == "" {
+				return fmt.Errorf("option %d in category %q has no name", j+1, category.Name)
+			}
+			if seenOptions[option.Name] {
+				return fmt.Errorf("duplicate option %q in category %q", option.Name, category.Name)
+			}
+			seenOptions[option.Name] = true
+
+			if !option.Stability.Valid() {
+				return fmt.Errorf("invalid stability %q in option %q, expected %q or %q", option.Stability, option.Name, StabilityRecommended, StabilityExperimental)
+			}
+			if !option.BuildTime.Valid() {
+				return fmt.Errorf("invalid build time %q in option %q, expected %q, %q or %q", option.BuildTime, option.Name, BuildTimeFast, BuildTimeMedium, BuildTimeSlow)
+			}
+
+			if len(option.Packages) == 0 {
+				return fmt.Errorf("option %q in category %q has no packages", option.Name, category.Name)
+			}
+			for _, pkg := range option.Packages {
+				if !ValidPackageName(pkg) {
+					return fmt.Errorf("invalid package name %q in option %q", pkg, option.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// MergeCategories returns the built-in categories with custom ones merged in.
+// A custom category replaces a built-in category of the same name; any other
+// custom category is appended.
+func MergeCategories(builtin, custom []PackageCategory) []PackageCategory {
+	merged := append([]PackageCategory{}, builtin...)
+	for _, category := range custom {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == category.Name {
+				merged[i] = category
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, category)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DefaultsPath returns the location of the user's defaults file
+func DefaultsPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "lunaris-installer", "config.toml")
+}
+
+// LoadDefaults returns the default options overridden by the user's defaults file, if any
+func LoadDefaults() (Options, error) {
+	opts := DefaultOptions()
+
+	path := DefaultsPath()
+	if path == "" {
+		return opts, nil
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return opts, nil
+	}
+
+	values, err := parseConfigFile(path)
+	if err != nil {
+		return opts, err
+	}
+
+	if err := applyDefaults(&opts, values); err != nil {
+		return opts, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return opts, nil
+}
+
+// keysTable is the config file table key bindings are read from
+const keysTable = "keys"
+
+// parseConfigFile reads the TOML config file into the values applyDefaults
+// parses. Keys in the [keys] table are returned as "keys.<action>" and lists
+// are joined with commas.
+func parseConfigFile(path string) (map[string]string, error) {
+	var file map[string]any
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		// Syntax errors name the line
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	for key, value := range file {
+		if key != keysTable {
+			text, err := configValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+			}
+			values[key] = text
+			continue
+		}
+
+		bindings, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: %s must be a table", path, keysTable)
+		}
+		for action, keys := range bindings {
+			text, err := configValue(keys)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s.%s: %w", path, keysTable, action, err)
+			}
+			values[keysTable+"."+action] = text
+		}
+	}
+
+	return values, nil
+}
+
+// configValue converts a decoded TOML value to the text applyDefaults parses
+func configValue(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			text, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("lists may only contain strings")
+			}
+			items[i] = text
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	// Tables other than [keys] and dates aren't options
+	return "", fmt.Errorf("unsupported value %v", value)
+}
+
+// applyDefaults applies parsed config values to the options
+func applyDefaults(opts *Options, values map[string]string) error {
+	for key, value := range values {
+		switch key {
+		case "aur_helper":
+			opts.AURHelper = value
+		case "log_path":
+			opts.LogPath = value
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid timeout %q: %w", value, err)
+			}
+			opts.Timeout = timeout
+		case "idle_timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid idle_timeout %q: %w", value, err)
+			}
+			opts.IdleTimeout = timeout
+		case "bell":
+			bell, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for bell %q: %w", value, err)
+			}
+			opts.Bell = bell
+		case "check_availability":
+			check, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for check_availability %q: %w", value, err)
+			}
+			opts.CheckAvailability = check
+		case "animations":
+			animations, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for animations %q: %w", value, err)
+			}
+			opts.Animations = animations
+		case "no_altscreen":
+			noAltScreen, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for no_altscreen %q: %w", value, err)
+			}
+			opts.NoAltScreen = noAltScreen
+		case "ascii":
+			ascii, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for ascii %q: %w", value, err)
+			}
+			// The high-contrast theme implies ASCII whichever key comes first
+			opts.ASCII = ascii || values["theme"] == ThemeHighContrast
+		case "theme":
+			if !ValidTheme(value) {
+				return fmt.Errorf("invalid value for theme %q: must be one of %s", value, strings.Join(Themes, ", "))
+			}
+			opts.ASCII = opts.ASCII || value == ThemeHighContrast
+		case "spinner":
+			if !ValidSpinner(value) {
+				return fmt.Errorf("invalid value for spinner %q: must be one of %s", value, strings.Join(SpinnerStyles, ", "))
+			}
+			opts.Spinner = value
+		case "services":
+			opts.Services = ParseList(value)
+		case "categories":
+			opts.CategoriesPath = value
+		case "download_first":
+			downloadFirst, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for download_first %q: %w", value, err)
+			}
+			opts.DownloadFirst = downloadFirst
+		case "strict":
+			strict, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for strict %q: %w", value, err)
+			}
+			opts.Strict = strict
+		case "sort_deps":
+			sortDeps, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for sort_deps %q: %w", value, err)
+			}
+			opts.SortDependencies = sortDeps
+		case "reinstall":
+			reinstall, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for reinstall %q: %w", value, err)
+			}
+			opts.Reinstall = reinstall
+		case "make_jobs":
+			makeJobs, err := strconv.Atoi(value)
+			if err != nil || makeJobs < 0 {
+				return fmt.Errorf("invalid value for make_jobs %q", value)
+			}
+			opts.MakeJobs = makeJobs
+		case "nice":
+			nice, err := strconv.Atoi(value)
+			if err != nil || !ValidNice(nice) {
+				return fmt.Errorf("invalid value for nice %q: must be between 0 and 19", value)
+			}
+			opts.Nice = nice
+		case "no_ionice":
+			noIONice, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for no_ionice %q: %w", value, err)
+			}
+			opts.NoIONice = noIONice
+		case "max_messages":
+			limit, err := strconv.Atoi(value)
+			if err != nil || !ValidMessageLimit(limit) {
+				return fmt.Errorf("invalid value for max_messages %q: must be at least 2", value)
+			}
+			opts.MessageLimit = limit
+		case "truncation_message":
+			opts.TruncationMessage = value
+		case "truncation_head":
+			head, err := strconv.ParseFloat(value, 64)
+			if err != nil || !ValidTruncationHead(head) {
+				return fmt.Errorf("invalid value for truncation_head %q: must be at least 0 and below 1", value)
+			}
+			opts.TruncationHead = head
+		case "verbosity":
+			if !ValidVerbosity(Verbosity(value)) {
+				return fmt.Errorf("invalid value for verbosity %q: must be quiet, normal or verbose", value)
+			}
+			opts.Verbosity = Verbosity(value)
+		case "extra":
+			opts.ExtraPackages = ParseList(value)
+			if err := ValidatePackageNames(opts.ExtraPackages); err != nil {
+				return fmt.Errorf("invalid value for extra: %w", err)
+			}
+		case "exclude":
+			opts.ExcludedPackages = ParseList(value)
+			if err := ValidatePackageNames(opts.ExcludedPackages); err != nil {
+				return fmt.Errorf("invalid value for exclude: %w", err)
+			}
+		case "no_reclone":
+			noReclone, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for no_reclone %q: %w", value, err)
+			}
+			opts.NoReclone = noReclone
+		case "use_existing":
+			useExisting, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for use_existing %q: %w", value, err)
+			}
+			opts.UseExisting = useExisting
+		case "full_clone":
+			fullClone, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for full_clone %q: %w", value, err)
+			}
+			opts.FullClone = fullClone
+		case "repo":
+			opts.Repo = value
+		case "clean_repo":
+			cleanRepo, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value for clean_repo %q: %w", value, err)
+			}
+			opts.CleanRepo = cleanRepo
+		default:
+			if action, ok := strings.CutPrefix(key, keysTable+"."); ok {
+				if opts.KeyBindings == nil {
+					opts.KeyBindings = make(map[string][]string)
+				}
+				opts.KeyBindings[action] = ParseList(value)
+				continue
+			}
+			return fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file to a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseConfigFile(t *testing.T) {
+	path := writeConfig(t, `
+# Comments and blank lines are skipped
+aur_helper = "paru" # A comment after a value
+truncation_message = "-- \"older\" output # dropped --"
+services = ["NetworkManager.service", "bluetooth.service"]
+animations = false
+make_jobs = 4
+truncation_head = 0.5
+
+[keys]
+quit = "ctrl+c, q"
+up = ["up", "k"]
+`)
+
+	values, err := parseConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"aur_helper":         "paru",
+		"truncation_message": `-- "older" output # dropped --`,
+		"services":           "NetworkManager.service,bluetooth.service",
+		"animations":         "false",
+		"make_jobs":          "4",
+		"truncation_head":    "0.5",
+		"keys.quit":          "ctrl+c, q",
+		"keys.up":            "up,k",
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %q, want %q", key, values[key], value)
+		}
+	}
+	if len(values) != len(want) {
+		t.Errorf("parseConfigFile() = %v, want %d values", values, len(want))
+	}
+}
+
+func TestParseConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unterminated string", "aur_helper = \"paru\ntimeout = \"45m\"\n", "line 1"},
+		{"missing value", "aur_helper = \"paru\"\ntimeout = 45m\n", "line 2"},
+		{"list of numbers", "services = [1, 2]\n", "services: lists may only contain strings"},
+		{"other table", "[colors]\naccent = \"blue\"\n", "colors: unsupported value"},
+		{"keys not a table", "keys = \"q\"\n", "keys must be a table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfigFile(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseConfigFile() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsTheme(t *testing.T) {
+	for _, values := range []map[string]string{
+		{"theme": ThemeHighContrast},
+		{"theme": ThemeHighContrast, "ascii": "false"},
+	} {
+		opts := DefaultOptions()
+		if err := applyDefaults(&opts, values); err != nil {
+			t.Fatal(err)
+		}
+		if !opts.ASCII {
+			t.Errorf("applyDefaults(%v) left ASCII off", values)
+		}
+	}
+
+	opts := DefaultOptions()
+	if err := applyDefaults(&opts, map[string]string{"theme": "neon"}); err == nil || !strings.Contains(err.Error(), strings.Join(Themes, ", ")) {
+		t.Errorf("applyDefaults() error = %v, want one listing the themes", err)
+	}
+	if err := applyDefaults(&opts, map[string]string{"services": "a.service,b.service"}); err != nil || !slices.Equal(opts.Services, []string{"a.service", "b.service"}) {
+		t.Errorf("applyDefaults() services = %v, %v", opts.Services, err)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// RequiredServices are the systemd units HyprLuna needs running
+var RequiredServices = []string{
+	"NetworkManager.service",
+	"power-profiles-daemon.service",
+	"bluetooth.service",
+}
+
+// Options holds the runtime options for the installer
+type Options struct {
+	// AssumeYes automatically accepts all confirmation prompts
+	AssumeYes bool
+
+	// Force allows destructive operations (such as deleting an existing
+	// ~/HyprLuna checkout) without confirmation when combined with AssumeYes
+	Force bool
+
+	// Resume continues the package installation of an interrupted run from its checkpoint
+	Resume bool
+
+	// SummaryPath is where the JSON exit summary is written; empty uses the default location
+	SummaryPath string
+
+	// AURHelper preselects an AUR helper on the AUR helper page
+	AURHelper string
+
+	// LogPath is a file every command output message is appended to; empty disables logging
+	LogPath string
+
+	// Timeout is how long a single install command may run before it is killed
+	Timeout time.Duration
+
+	// IdleTimeout aborts the installation after this long without progress or
+	// key presses; 0 disables it. With AssumeYes the installer then quits.
+	IdleTimeout time.Duration
+
+	// Bell rings the terminal bell and sends a desktop notification when the
+	// installer waits for the sudo password
+	Bell bool
+
+	// CheckAvailability looks up the selected packages in the repositories and
+	// the AUR before installing, so missing ones are flagged up front
+	CheckAvailability bool
+
+	// Animations enables page transition animations
+	Animations bool
+
+	// NoAltScreen renders inline instead of on the alternate screen, for
+	// terminals and multiplexers that don't support it well
+	NoAltScreen bool
+
+	// ASCII draws the UI with ASCII markers and a black-and-white palette
+	ASCII bool
+
+	// Spinner is the style of the spinner, one of SpinnerStyles; empty uses
+	// dot, or line with ASCII
+	Spinner string
+
+	// Services are the systemd units enabled and started after installation
+	Services []string
+
+	// NoReclone updates an existing ~/HyprLuna checkout of the config repository instead of cloning it again
+	NoReclone bool
+
+	// UseExisting installs the dotfiles from an existing ~/HyprLuna as it is,
+	// without cloning or updating it, for applying local changes
+	UseExisting bool
+
+	// FullClone clones the config repository with its full history instead of
+	// only the latest commit, for contributing back to it
+	FullClone bool
+
+	// Repo is the URL of the configuration repository; git@ and ssh:// URLs
+	// are cloned over SSH with the user's keys
+	Repo string
+
+	// GitToken authenticates HTTPS clones of a private Repo. It is only
+	// handed to git through the environment and never logged.
+	GitToken string
+
+	// BackupLabel names the backup of the existing configuration, next to its
+	// timestamp, so it can be told apart when restoring
+	BackupLabel string
+
+	// CleanRepo deletes the cloned ~/HyprLuna repository once the configuration has been copied
+	CleanRepo bool
+
+	// CategoriesPath is a JSON file of additional package categories; empty uses only the built-in ones
+	CategoriesPath string
+
+	// CustomCategories are the categories loaded from CategoriesPath
+	CustomCategories []PackageCategory
+
+	// DownloadFirst downloads every package into the pacman cache before installing any of them
+	DownloadFirst bool
+
+	// Strict aborts the installation on the first package that fails to
+	// install instead of collecting the failures and continuing
+	Strict bool
+
+	// SortDependencies installs packages after the selected packages they depend on
+	SortDependencies bool
+
+	// Reinstall installs the selected packages even when they are up to date,
+	// to repair broken installs
+	Reinstall bool
+
+	// MakeJobs is the number of parallel make and cargo build jobs; 0 keeps the conservative defaults
+	MakeJobs int
+
+	// Nice is the niceness package builds run with
+	Nice int
+
+	// NoIONice runs package builds without the idle I/O priority
+	NoIONice bool
+
+	// MessageLimit is how many output messages are kept in memory
+	MessageLimit int
+
+	// TruncationMessage replaces the messages dropped once MessageLimit is reached; empty uses the default
+	TruncationMessage string
+
+	// TruncationHead is the share of MessageLimit, between 0 and 1, kept from the start of the output
+	TruncationHead float64
+
+	// Verbosity controls which command output messages are shown
+	Verbosity Verbosity
+
+	// KeyBindings replace the keys of the named actions, e.g. "up" or "quit"
+	KeyBindings map[string][]string
+
+	// ExtraPackages are installed in addition to the packages of the selected options
+	ExtraPackages []string
+
+	// ExcludedPackages are never installed, even as base packages or
+	// packages of the selected options
+	ExcludedPackages []string
+}
+
+// DefaultOptions returns the default installer options
+func DefaultOptions() Options {
+	return Options{
+		AssumeYes:      false,
+		Force:          false,
+		SummaryPath:    "",
+		AURHelper:      "",
+		LogPath:        "",
+		Timeout:        30 * time.Minute,
+		Animations:     true,
+		Nice:           19,
+		Repo:           ConfigRepo,
+		MessageLimit:   100,
+		TruncationHead: 0.25,
+		Verbosity:      VerbosityNormal,
+		Services:       append([]string(nil), RequiredServices...),
+	}
+}
+
+// ParseList splits a comma-separated list, ignoring surrounding quotes,
+// brackets and empty entries
+func ParseList(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "[")
+	value = strings.TrimSuffix(value, "]")
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"'`)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ValidMessageLimit reports whether limit leaves room for the truncation message and output
+func ValidMessageLimit(limit int) bool {
+	return limit >= 2
+}
+
+// ValidTruncationHead reports whether head is a share of the messages that can be kept from the start
+func ValidTruncationHead(head float64) bool {
+	return head >= 0 && head < 1
+}
+
+// Verbosity is how much command output is shown
+type Verbosity string
+
+// Verbosities of the command output
+const (
+	// VerbosityQuiet only shows warnings, errors and successes
+	VerbosityQuiet Verbosity = "quiet"
+
+	// VerbosityNormal shows everything but debug output
+	VerbosityNormal Verbosity = "normal"
+
+	// VerbosityVerbose shows everything
+	VerbosityVerbose Verbosity = "verbose"
+)
+
+// Verbosities lists the verbosities from the least to the most output
+var Verbosities = []Verbosity{VerbosityQuiet, VerbosityNormal, VerbosityVerbose}
+
+// ValidVerbosity reports whether v is one of Verbosities
+func ValidVerbosity(v Verbosity) bool {
+	return slices.Contains(Verbosities, v)
+}
+
+// SpinnerStyles are the names of the spinner styles
+var SpinnerStyles = []string{"line", "dot", "minidot", "jump", "pulse", "points", "globe", "moon", "monkey", "meter", "hamburger", "ellipsis"}
+
+// ValidSpinner reports whether name is one of SpinnerStyles
+func ValidSpinner(name string) bool {
+	return slices.Contains(SpinnerStyles, name)
+}
+
+// Themes are the names of the color themes; ThemeHighContrast is the
+// black-and-white palette of ASCII
+var Themes = []string{ThemeDefault, ThemeHighContrast}
+
+// Names of the color themes
+const (
+	ThemeDefault      = "default"
+	ThemeHighContrast = "high-contrast"
+)
+
+// ValidTheme reports whether name is one of Themes
+func ValidTheme(name string) bool {
+	return slices.Contains(Themes, name)
+}
+
+// ValidNice reports whether nice is a niceness an unprivileged user can set
+func ValidNice(nice int) bool {
+	return nice >= 0 && nice <= 19
+}
@@ -1,7 +1,13 @@
 package config
 
-// AURHelpers is a list of available AUR helpers
-var AURHelpers = []string{"yay", "paru"}
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// AURHelpers is a list of available AUR helpers; "none" installs with pacman only
+var AURHelpers = []string{"yay", "paru", "none"}
 
 // BasePackages is a list of base packages that are always installed
 var BasePackages = []string{
@@ -124,6 +130,93 @@ type PackageOption struct {
 	Description string
 	Packages    []string
 	Default     bool
+	Services    []string // systemd units to enable after installation
+	Tags        []string // e.g. "lightweight", used to filter options across categories
+	Stability   Stability
+	BuildTime   BuildTime // How long the option takes to build, derived from its packages when empty
+}
+
+// Stability tells how reliable a package option is
+type Stability string
+
+// Stabilities of package options
+const (
+	// StabilityDefault is for options without a stability set
+	StabilityDefault Stability = ""
+
+	// StabilityRecommended marks options known to work well with HyprLuna
+	StabilityRecommended Stability = "recommended"
+
+	// StabilityExperimental marks bleeding-edge options that may break
+	StabilityExperimental Stability = "experimental"
+)
+
+// Valid reports whether s is one of the known stabilities
+func (s Stability) Valid() bool {
+	return s == StabilityDefault || s == StabilityRecommended || s == StabilityExperimental
+}
+
+// Experimental reports whether the option may break. Options without a
+// stability are experimental when they install -git or -bin packages, which
+// track upstream instead of tested releases.
+func (o PackageOption) Experimental() bool {
+	if o.Stability != StabilityDefault {
+		return o.Stability == StabilityExperimental
+	}
+	for _, pkg := range o.Packages {
+		if strings.HasSuffix(pkg, "-git") || strings.HasSuffix(pkg, "-bin") {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildTime is a rough bucket of how long an option takes to build and install
+type BuildTime string
+
+// Build times of package options
+const (
+	// BuildTimeDefault is for options without a build time set
+	BuildTimeDefault BuildTime = ""
+
+	// BuildTimeFast is for prebuilt packages that are only downloaded
+	BuildTimeFast BuildTime = "fast"
+
+	// BuildTimeMedium is for packages that take a few minutes to build
+	BuildTimeMedium BuildTime = "medium"
+
+	// BuildTimeSlow is for packages compiled from large sources
+	BuildTimeSlow BuildTime = "slow"
+)
+
+// Valid reports whether b is one of the known build times
+func (b BuildTime) Valid() bool {
+	return b == BuildTimeDefault || b == BuildTimeFast || b == BuildTimeMedium || b == BuildTimeSlow
+}
+
+// Estimate returns a rough duration of a build taking b
+func (b BuildTime) Estimate() time.Duration {
+	switch b {
+	case BuildTimeMedium:
+		return 5 * time.Minute
+	case BuildTimeSlow:
+		return 20 * time.Minute
+	}
+	return 0
+}
+
+// EstimatedBuildTime returns the build time of the option. Options without a
+// build time are slow when they build -git packages from source, and fast otherwise.
+func (o PackageOption) EstimatedBuildTime() BuildTime {
+	if o.BuildTime != BuildTimeDefault {
+		return o.BuildTime
+	}
+	for _, pkg := range o.Packages {
+		if strings.HasSuffix(pkg, "-git") {
+			return BuildTimeSlow
+		}
+	}
+	return BuildTimeFast
 }
 
 // PackageCategories is a list of package categories
@@ -137,18 +230,21 @@ var PackageCategories = []PackageCategory{
 				Description: "A fast, cross-platform, OpenGL terminal emulator",
 				Packages:    []string{"alacritty"},
 				Default:     true,
+				Tags:        []string{"gpu"},
 			},
 			{
 				Name:        "Kitty",
 				Description: "A modern, hackable, featureful, OpenGL-based terminal emulator",
 				Packages:    []string{"kitty"},
 				Default:     false,
+				Tags:        []string{"gpu"},
 			},
 			{
 				Name:        "Foot",
 				Description: "A fast, lightweight and minimalistic Wayland terminal emulator",
 				Packages:    []string{"foot"},
 				Default:     false,
+				Tags:        []string{"lightweight", "wayland-native"},
 			},
 		},
 		Required: false,
@@ -174,6 +270,7 @@ var PackageCategories = []PackageCategory{
 				Description: "The default shell for most Linux distributions",
 				Packages:    []string{"bash", "bash-completion"},
 				Default:     false,
+				Tags:        []string{"lightweight"},
 			},
 		},
 		Required: false,
@@ -212,18 +309,21 @@ var PackageCategories = []PackageCategory{
 				Description: "A modern file manager for the Xfce Desktop Environment",
 				Packages:    []string{"thunar", "thunar-archive-plugin", "thunar-volman", "tumbler"},
 				Default:     true,
+				Tags:        []string{"lightweight", "gtk"},
 			},
 			{
 				Name:        "Dolphin",
 				Description: "The default file manager for the KDE Plasma desktop",
 				Packages:    []string{"dolphin"},
 				Default:     false,
+				Tags:        []string{"qt"},
 			},
 			{
 				Name:        "Nautilus",
 				Description: "The default file manager for the GNOME desktop",
 				Packages:    []string{"nautilus"},
 				Default:     false,
+				Tags:        []string{"gtk"},
 			},
 		},
 		Required: false,
@@ -237,6 +337,7 @@ var PackageCategories = []PackageCategory{
 				Description: "Hyperextensible Vim-based text editor",
 				Packages:    []string{"neovim"},
 				Default:     true,
+				Tags:        []string{"lightweight", "terminal"},
 			},
 			{
 				Name:        "Visual Studio Code",
@@ -249,6 +350,30 @@ var PackageCategories = []PackageCategory{
 				Description: "A text editor for the GNOME desktop environment",
 				Packages:    []string{"gedit"},
 				Default:     false,
+				Tags:        []string{"gtk"},
+			},
+		},
+		Required: false,
+	},
+	{
+		Name:        "Session",
+		Description: "Display managers to log in to HyprLuna",
+		Options: []PackageOption{
+			{
+				Name:        "SDDM",
+				Description: "A QML based display manager",
+				Packages:    []string{"sddm"},
+				Default:     false,
+				Services:    []string{"sddm.service"},
+				Tags:        []string{"qt"},
+			},
+			{
+				Name:        "Ly",
+				Description: "A lightweight TUI display manager",
+				Packages:    []string{"ly"},
+				Default:     false,
+				Services:    []string{"ly.service"},
+				Tags:        []string{"lightweight", "terminal"},
 			},
 		},
 		Required: false,
@@ -262,24 +387,108 @@ var PackageCategories = []PackageCategory{
 				Description: "A free and open source cross-platform multimedia player",
 				Packages:    []string{"vlc"},
 				Default:     true,
+				Tags:        []string{"qt"},
 			},
 			{
 				Name:        "MPV",
 				Description: "A free, open source, and cross-platform media player",
 				Packages:    []string{"mpv"},
 				Default:     false,
+				Tags:        []string{"lightweight", "gpu"},
 			},
 			{
 				Name:        "Celluloid",
 				Description: "A simple GTK+ frontend for mpv",
 				Packages:    []string{"celluloid"},
 				Default:     false,
+				Tags:        []string{"gtk"},
 			},
 		},
 		Required: false,
 	},
 }
 
+// SessionFile is the wayland session entry display managers use to start HyprLuna
+const SessionFile = "/usr/share/wayland-sessions/hyprluna.desktop"
+
+// SessionFileContents is the contents of SessionFile
+const SessionFileContents = `[Desktop Entry]
+Name=HyprLuna
+Comment=Hyprland with the HyprLuna configuration
+Exec=Hyprland
+Type=Application
+`
+
+// FragileTag tags the base packages IsFragileBasePackage reports, so they
+// can be listed with the tag filter in advanced mode
+const FragileTag = "may-fail"
+
+// fragileBasePackages are base packages that are known to fail for some users
+var fragileBasePackages = []string{
+	"apple-fonts",
+}
+
+// IsFragileBasePackage reports whether pkg is a base package known to fail to
+// build or conflict on some systems, such as packages built from git
+func IsFragileBasePackage(pkg string) bool {
+	return strings.HasSuffix(pkg, "-git") || slices.Contains(fragileBasePackages, pkg)
+}
+
+// BasePackagesCategoryName is the name of the category exposing base packages in advanced mode
+const BasePackagesCategoryName = "Base Packages"
+
+// BasePackagesCategory returns the base packages as a category of individually selectable options
+func BasePackagesCategory() PackageCategory {
+	category := PackageCategory{
+		Name:        BasePackagesCategoryName,
+		Description: "Packages HyprLuna depends on (advanced: deselect at your own risk)",
+		Options:     []PackageOption{},
+		Required:    true,
+	}
+
+	seen := make(map[string]bool)
+	for _, pkg := range BasePackages {
+		if seen[pkg] {
+			continue
+		}
+		seen[pkg] = true
+
+		option := PackageOption{
+			Name:        pkg,
+			Description: "Base package",
+			Packages:    []string{pkg},
+			Default:     true,
+		}
+		if IsFragileBasePackage(pkg) {
+			option.Description = "Base package, known to fail to build or conflict on some systems"
+			option.Tags = []string{FragileTag}
+		}
+		category.Options = append(category.Options, option)
+	}
+
+	return category
+}
+
+// BackupDir is the directory, relative to the home directory, BackupDirs are backed up to
+const BackupDir = "HyprLuna-User-Bak"
+
+// BackupDirs is a list of home directories backed up before installing dotfiles
+var BackupDirs = []string{
+	".config",
+	".local",
+	".ags",
+}
+
+// WallpapersDir is the directory, relative to the home directory, wallpapers are offered from after installing dotfiles
+const WallpapersDir = "Pictures/wallpapers"
+
+// InstructionsFiles are the files, relative to the config repository, the
+// complete page reads post-install instructions from, in order of preference
+var InstructionsFiles = []string{
+	"POST_INSTALL.md",
+	"instructions.txt",
+}
+
 // ConfigDirs is a list of configuration directories to copy
 var ConfigDirs = []string{
 	".config",
@@ -290,3 +499,10 @@ var ConfigDirs = []string{
 	".cursor",
 	".vscode",
 }
+
+// RequiredConfigPaths are the directories, relative to the config repository,
+// an existing ~/HyprLuna must have to be installed without cloning it
+var RequiredConfigPaths = []string{
+	".config/hypr",
+	".config/ags",
+}
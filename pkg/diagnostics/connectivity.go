@@ -0,0 +1,89 @@
+package diagnostics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// aurURL is the AUR address tested by TestConnectivity
+const aurURL = "https://aur.archlinux.org/"
+
+// defaultMirrorURL is tested when the mirrorlist has no usable server
+const defaultMirrorURL = "https://geo.mirror.pkgbuild.com/"
+
+// mirrorlistPath is the pacman mirrorlist the tested mirror is read from
+var mirrorlistPath = "/etc/pacman.d/mirrorlist"
+
+// connectivityTimeout is how long each host may take to respond
+const connectivityTimeout = 10 * time.Second
+
+// Result is the outcome of reaching one host
+type Result struct {
+	Name    string        // What the host is used for, e.g. AUR
+	URL     string        // Address that was requested
+	Latency time.Duration // Time until the response, when it was reached
+	Err     error
+}
+
+// String describes the result on a single line
+func (r Result) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s (%s): failed: %v", r.Name, r.URL, r.Err)
+	}
+	return fmt.Sprintf("%s (%s): %d ms", r.Name, r.URL, r.Latency.Milliseconds())
+}
+
+// TestConnectivity requests the AUR and the first pacman mirror, timing the
+// responses, to tell network problems from package problems. Any HTTP
+// response counts as reached.
+func TestConnectivity() []Result {
+	client := &http.Client{Timeout: connectivityTimeout}
+	return []Result{
+		testURL(client, "AUR", aurURL),
+		testURL(client, "Pacman mirror", firstMirror()),
+	}
+}
+
+// testURL times a HEAD request to rawURL
+func testURL(client *http.Client, name, rawURL string) Result {
+	result := Result{Name: name, URL: rawURL}
+
+	start := time.Now()
+	resp, err := client.Head(rawURL)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	resp.Body.Close()
+	result.Latency = time.Since(start)
+	return result
+}
+
+// firstMirror returns the root of the first server in the pacman
+// mirrorlist, which is the mirror pacman tries first
+func firstMirror() string {
+	file, err := os.Open(mirrorlistPath)
+	if err != nil {
+		return defaultMirrorURL
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.TrimSpace(key) != "Server" {
+			continue
+		}
+		server, err := url.Parse(strings.TrimSpace(value))
+		if err != nil || server.Host == "" {
+			continue
+		}
+		return server.Scheme + "://" + server.Host + "/"
+	}
+	return defaultMirrorURL
+}
@@ -0,0 +1,121 @@
+package diagnostics
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/system"
+)
+
+// Report holds the installer state included in a diagnostics report
+type Report struct {
+	AURHelper         string
+	AURHelperCommand  string
+	SelectedOptions   map[string][]string
+	InstalledPackages []string
+	FailedPackages    []string
+	SkippedPackages   []string
+	LastError         string
+	LogPath           string
+	Output            string
+}
+
+// Collect assembles the installer state and system information into a
+// plain text report that can be attached to a bug report
+func Collect(r Report) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Lunaris Installer diagnostics (%s)\n", time.Now().Format(time.RFC3339))
+
+	// System information
+	writeSection(&b, "System")
+	fmt.Fprintf(&b, "Kernel: %s\n", commandOutput("uname", "-a"))
+	if distro, err := system.DetectDistro(); err == nil {
+		fmt.Fprintf(&b, "Distribution: %s\n", distro.Name())
+	} else {
+		fmt.Fprintf(&b, "Distribution: unknown (%v)\n", err)
+	}
+	if version, err := system.HyprlandVersion(); err == nil {
+		fmt.Fprintf(&b, "Hyprland: %s\n", version)
+	} else {
+		fmt.Fprintf(&b, "Hyprland: unknown (%v)\n", err)
+	}
+	fmt.Fprintf(&b, "AUR helper: %s\n", r.AURHelper)
+	if r.AURHelperCommand != "" {
+		fmt.Fprintf(&b, "AUR helper version: %s\n", commandOutput(r.AURHelperCommand, "--version"))
+	}
+
+	// Selected packages
+	writeSection(&b, "Selected Options")
+	categories := make([]string, 0, len(r.SelectedOptions))
+	for category := range r.SelectedOptions {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		fmt.Fprintf(&b, "%s: %s\n", category, strings.Join(r.SelectedOptions[category], ", "))
+	}
+
+	writeSection(&b, "Packages")
+	fmt.Fprintf(&b, "Installed: %s\n", strings.Join(r.InstalledPackages, " "))
+	fmt.Fprintf(&b, "Failed: %s\n", strings.Join(r.FailedPackages, " "))
+	fmt.Fprintf(&b, "Skipped: %s\n", strings.Join(r.SkippedPackages, " "))
+
+	// Last error
+	writeSection(&b, "Last Error")
+	if r.LastError != "" {
+		b.WriteString(r.LastError + "\n")
+	} else {
+		b.WriteString("none\n")
+	}
+
+	// Install log
+	writeSection(&b, "Install Log")
+	if r.LogPath != "" {
+		fmt.Fprintf(&b, "Full log: %s\n\n", r.LogPath)
+	}
+	b.WriteString(r.Output)
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// Write saves a report to a timestamped file in the user's cache directory
+// and returns its path
+func Write(report string) (string, error) {
+	dir := os.TempDir()
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		dir = filepath.Join(cacheDir, "lunaris-installer")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create diagnostics directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("diagnostics-%s.txt", time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+		return "", fmt.Errorf("failed to write diagnostics: %w", err)
+	}
+
+	return path, nil
+}
+
+// writeSection writes a section header
+func writeSection(b *strings.Builder, title string) {
+	fmt.Fprintf(b, "\n== %s ==\n", title)
+}
+
+// commandOutput returns the first line of a command's output, or the error
+func commandOutput(name string, args ...string) string {
+	output, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return fmt.Sprintf("unavailable (%v)", err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return line
+}
@@ -0,0 +1,150 @@
+package manifest
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/utils"
+)
+
+// DefaultPath returns the location of the manifest of installed dotfiles
+func DefaultPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "lunaris-installer", "manifest.txt")
+}
+
+// Files returns the files under root/dir as paths relative to root
+func Files(root, dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(filepath.Join(root, dir), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// Read returns the relative paths recorded in the manifest at path
+func Read(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+
+	var files []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Never touch anything outside the home directory
+		if !filepath.IsLocal(line) {
+			return nil, fmt.Errorf("invalid path in manifest: %s", line)
+		}
+		files = append(files, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return files, nil
+}
+
+// Write records files in the manifest at path, keeping the files recorded by
+// earlier installs since they are still on disk
+func Write(path string, files []string) error {
+	seen := make(map[string]bool)
+	if existing, err := Read(path); err == nil {
+		for _, file := range existing {
+			seen[file] = true
+		}
+	}
+	for _, file := range files {
+		seen[file] = true
+	}
+
+	all := make([]string, 0, len(seen))
+	for file := range seen {
+		all = append(all, file)
+	}
+	sort.Strings(all)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	content := strings.Join(all, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes files, relative to homeDir, and the directories left empty
+// by them. When backupDir is set, files found in it are copied back instead.
+// Progress is reported line by line to onOutput.
+func Remove(homeDir string, files []string, backupDir string, onOutput func(string)) error {
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		target := filepath.Join(homeDir, file)
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", target, err)
+		}
+		dirs[filepath.Dir(target)] = true
+
+		if backupDir == "" {
+			continue
+		}
+		backup := filepath.Join(backupDir, file)
+		if _, err := os.Stat(backup); err != nil {
+			continue
+		}
+		if err := utils.CopyFile(backup, target); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", target, err)
+		}
+		onOutput(fmt.Sprintf("Restored %s", file))
+	}
+
+	onOutput(fmt.Sprintf("Removed %d files", len(files)))
+
+	// Remove the deepest directories first so their parents can become empty
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	for _, dir := range sorted {
+		for dir != homeDir && strings.HasPrefix(dir, homeDir+string(filepath.Separator)) {
+			// Remove fails on directories that aren't empty
+			if os.Remove(dir) != nil {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	return nil
+}
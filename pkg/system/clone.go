@@ -0,0 +1,62 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// cloneProgressPattern matches git's progress lines, e.g. "Receiving objects:  45% (90/200)"
+var cloneProgressPattern = regexp.MustCompile(`^(?:remote: )?([A-Z][a-z]+ [a-z]+):\s+(\d+)%`)
+
+// cloneProgress holds the progress of the git clone currently running
+var cloneProgress struct {
+	sync.Mutex
+	stage   string
+	percent int
+	active  bool
+}
+
+// ScanProgressLines is a bufio.SplitFunc like bufio.ScanLines that also
+// splits on the carriage returns git uses to redraw its progress
+func ScanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	return bufio.ScanLines(data, atEOF)
+}
+
+// TrackCloneProgress records the stage and percentage of a git progress
+// line and reports whether line was one
+func TrackCloneProgress(line string) bool {
+	match := cloneProgressPattern.FindStringSubmatch(line)
+	if match == nil {
+		return false
+	}
+
+	percent, _ := strconv.Atoi(match[2])
+
+	cloneProgress.Lock()
+	defer cloneProgress.Unlock()
+	cloneProgress.stage = match[1]
+	cloneProgress.percent = percent
+	cloneProgress.active = true
+	return true
+}
+
+// EndCloneProgress marks the running clone as finished
+func EndCloneProgress() {
+	cloneProgress.Lock()
+	defer cloneProgress.Unlock()
+	cloneProgress.active = false
+}
+
+// CloneProgress returns the stage and percentage of the running clone, ok
+// is false when no clone is reporting progress
+func CloneProgress() (stage string, percent int, ok bool) {
+	cloneProgress.Lock()
+	defer cloneProgress.Unlock()
+	return cloneProgress.stage, cloneProgress.percent, cloneProgress.active
+}
@@ -0,0 +1,99 @@
+package system
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RequireGit checks that git is available before any repository is cloned
+func RequireGit() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is required; install with sudo pacman -S git")
+	}
+	return nil
+}
+
+// IsGitCheckoutOf reports whether dir is a git checkout whose origin remote is url
+func IsGitCheckoutOf(dir, url string) bool {
+	output, err := exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return false
+	}
+	return normalizeRemote(string(output)) == normalizeRemote(url)
+}
+
+// normalizeRemote strips the parts of a remote URL that don't identify the repository
+func normalizeRemote(url string) string {
+	url = strings.TrimSpace(url)
+	url = strings.TrimSuffix(url, "/")
+	url = strings.TrimSuffix(url, ".git")
+	return strings.ToLower(url)
+}
+
+// gitTokenEnv is the environment variable the credential helper set up by
+// GitAuth reads the token from
+const gitTokenEnv = "LUNARIS_INSTALLER_GIT_TOKEN"
+
+// IsSSHRemote reports whether remote is cloned over SSH, e.g.
+// git@github.com:user/repo.git or ssh://git@github.com/user/repo.git
+func IsSSHRemote(remote string) bool {
+	if strings.HasPrefix(remote, "ssh://") {
+		return true
+	}
+	return !strings.Contains(remote, "://") && strings.Contains(remote, ":")
+}
+
+// RemoteAddress returns the host:port address git connects to for remote
+func RemoteAddress(remote string) string {
+	if IsSSHRemote(remote) && !strings.HasPrefix(remote, "ssh://") {
+		// scp-like syntax: [user@]host:path
+		host, _, _ := strings.Cut(remote, ":")
+		if _, after, ok := strings.Cut(host, "@"); ok {
+			host = after
+		}
+		return net.JoinHostPort(host, "22")
+	}
+
+	parsed, err := url.Parse(remote)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	if port := parsed.Port(); port != "" {
+		return net.JoinHostPort(parsed.Hostname(), port)
+	}
+	if parsed.Scheme == "ssh" {
+		return net.JoinHostPort(parsed.Hostname(), "22")
+	}
+	if parsed.Scheme == "http" {
+		return net.JoinHostPort(parsed.Hostname(), "80")
+	}
+	return net.JoinHostPort(parsed.Hostname(), "443")
+}
+
+// GitAuth returns the git options, to go before the git command, and the
+// environment for running git against remote without prompting. Over HTTPS a
+// non-empty token is handed to git by a credential helper that reads it from
+// the environment, so it never shows up in arguments or output. Over SSH the
+// user's agent and keys are used.
+func GitAuth(remote, token string) ([]string, []string) {
+	// Fail instead of prompting on the terminal the TUI is drawing on
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+
+	if IsSSHRemote(remote) {
+		// Keep a custom SSH command, e.g. one selecting a deploy key
+		if os.Getenv("GIT_SSH_COMMAND") == "" {
+			env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+		}
+		return nil, env
+	}
+
+	if token == "" {
+		return nil, env
+	}
+	helper := fmt.Sprintf(`!f() { test "$1" = get && echo username=x-access-token && echo "password=$%s"; }; f`, gitTokenEnv)
+	return []string{"-c", "credential.helper=" + helper}, append(env, gitTokenEnv+"="+token)
+}
@@ -0,0 +1,47 @@
+package system
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// Desktop is a desktop environment whose xdg-desktop-portal backend can be
+// picked over the Hyprland one
+type Desktop struct {
+	Name    string
+	Package string // Package that marks the desktop as installed
+	Portal  string // Portal backend package the desktop brings along
+}
+
+// knownDesktops are the desktops checked by InstalledDesktops
+var knownDesktops = []Desktop{
+	{Name: "GNOME", Package: "gnome-shell", Portal: "xdg-desktop-portal-gnome"},
+	{Name: "KDE Plasma", Package: "plasma-workspace", Portal: "xdg-desktop-portal-kde"},
+	{Name: "Xfce", Package: "xfce4-session", Portal: "xdg-desktop-portal-xapp"},
+	{Name: "Cinnamon", Package: "cinnamon", Portal: "xdg-desktop-portal-xapp"},
+	{Name: "Budgie", Package: "budgie-desktop", Portal: "xdg-desktop-portal-gtk"},
+}
+
+// InstalledDesktops returns the known desktop environments that are
+// installed together with their portal backend
+func InstalledDesktops() []Desktop {
+	args := []string{"-Qq", "--"}
+	for _, desktop := range knownDesktops {
+		args = append(args, desktop.Package, desktop.Portal)
+	}
+
+	// pacman fails when any of the packages is missing but still lists the others
+	output, _ := exec.Command("pacman", args...).Output()
+	installed := make(map[string]bool)
+	for _, name := range strings.Fields(string(output)) {
+		installed[name] = true
+	}
+
+	var desktops []Desktop
+	for _, desktop := range knownDesktops {
+		if installed[desktop.Package] && installed[desktop.Portal] {
+			desktops = append(desktops, desktop)
+		}
+	}
+	return desktops
+}
@@ -0,0 +1,75 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// osReleasePath is the location of the os-release file
+var osReleasePath = "/etc/os-release"
+
+// Distro describes the running Linux distribution
+type Distro struct {
+	ID         string
+	IDLike     []string
+	PrettyName string
+}
+
+// DetectDistro reads the running distribution from /etc/os-release
+func DetectDistro() (Distro, error) {
+	file, err := os.Open(osReleasePath)
+	if err != nil {
+		return Distro{}, fmt.Errorf("failed to read %s: %w", osReleasePath, err)
+	}
+	defer file.Close()
+
+	var distro Distro
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+
+		switch key {
+		case "ID":
+			distro.ID = value
+		case "ID_LIKE":
+			distro.IDLike = strings.Fields(value)
+		case "PRETTY_NAME":
+			distro.PrettyName = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Distro{}, fmt.Errorf("failed to parse %s: %w", osReleasePath, err)
+	}
+
+	return distro, nil
+}
+
+// IsArchBased checks if the distribution is Arch Linux or derived from it
+func (d Distro) IsArchBased() bool {
+	if d.ID == "arch" {
+		return true
+	}
+	for _, like := range d.IDLike {
+		if like == "arch" {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns a human-readable name for the distribution
+func (d Distro) Name() string {
+	if d.PrettyName != "" {
+		return d.PrettyName
+	}
+	if d.ID != "" {
+		return d.ID
+	}
+	return "unknown"
+}
@@ -0,0 +1,25 @@
+package system
+
+import (
+	"fmt"
+	"os"
+)
+
+// CheckHomeWritable creates and removes a temporary file in the home
+// directory, failing when backups and dotfiles couldn't be written to it
+func CheckHomeWritable() error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(homeDir, ".lunaris-installer-*")
+	if err != nil {
+		return fmt.Errorf("home directory %s is not writable: %w", homeDir, err)
+	}
+	file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", file.Name(), err)
+	}
+	return nil
+}
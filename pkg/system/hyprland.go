@@ -0,0 +1,44 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// hyprlandCommands are the names the Hyprland binary is installed as
+var hyprlandCommands = []string{"hyprland", "Hyprland"}
+
+// hyprlandVersionPattern matches the version in the output of Hyprland
+// --version: "Hyprland 0.45.2 built from branch ..." in recent releases,
+// and "... (tag: v0.36.0)" or "Tag: v0.34.0" after the commit in older ones
+var hyprlandVersionPattern = regexp.MustCompile(`(?:Hyprland |[Tt]ag: v)(\d+\.\d+(?:\.\d+)?)`)
+
+// HyprlandVersion returns the version of the installed Hyprland, e.g. 0.45.2
+func HyprlandVersion() (string, error) {
+	for _, name := range hyprlandCommands {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			continue
+		}
+
+		output, err := exec.Command(path, "--version").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to run %s --version: %w", name, err)
+		}
+		return parseHyprlandVersion(string(output))
+	}
+	return "", errors.New("no hyprland command found, Hyprland is not installed")
+}
+
+// parseHyprlandVersion returns the version in the output of Hyprland --version
+func parseHyprlandVersion(output string) (string, error) {
+	match := hyprlandVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+		return "", fmt.Errorf("unrecognized Hyprland version: %q", line)
+	}
+	return match[1], nil
+}
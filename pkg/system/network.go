@@ -0,0 +1,26 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrNoNetwork is returned when none of the checked hosts can be reached
+var ErrNoNetwork = errors.New("no network connectivity")
+
+// networkTimeout is how long to wait for each host before giving up
+const networkTimeout = 5 * time.Second
+
+// CheckNetwork dials each host:port address and fails fast if one of them is unreachable
+func CheckNetwork(addresses ...string) error {
+	for _, address := range addresses {
+		conn, err := net.DialTimeout("tcp", address, networkTimeout)
+		if err != nil {
+			return fmt.Errorf("%w: cannot reach %s: %v", ErrNoNetwork, address, err)
+		}
+		conn.Close()
+	}
+	return nil
+}
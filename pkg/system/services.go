@@ -0,0 +1,64 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runSudo runs a command as root, passing the password to sudo on stdin when one is set
+func runSudo(password string, args ...string) error {
+	var cmd *exec.Cmd
+	if password != "" {
+		cmd = exec.Command("sudo", append([]string{"-S", "-p", ""}, args...)...)
+		cmd.Stdin = strings.NewReader(password + "\n")
+	} else {
+		cmd = exec.Command("sudo", args...)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%s: %w", msg, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// EnableService enables a systemd unit, also starting it right away when now is set
+func EnableService(name, password string, now bool) error {
+	args := []string{"systemctl", "enable"}
+	if now {
+		args = append(args, "--now")
+	}
+	args = append(args, name)
+
+	if err := runSudo(password, args...); err != nil {
+		return fmt.Errorf("failed to enable %s: %w", name, err)
+	}
+	return nil
+}
+
+// InstallRootFile writes contents to a root-owned path with the given mode
+func InstallRootFile(path, contents, password string, mode os.FileMode) error {
+	tmp, err := os.CreateTemp("", "lunaris-installer-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(contents); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := runSudo(password, "install", "-D", "-m", fmt.Sprintf("%o", mode), tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to install %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package system
+
+import "os"
+
+// LiveSession returns the name of the graphical session the installer is
+// running in, or an empty string when it is not running in one
+func LiveSession() string {
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+		return "Hyprland"
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if desktop := os.Getenv("XDG_CURRENT_DESKTOP"); desktop != "" {
+			return desktop
+		}
+		return "Wayland"
+	}
+	return ""
+}
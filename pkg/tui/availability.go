@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AvailabilityMsg carries the result of looking up the selected packages
+type AvailabilityMsg struct {
+	Packages string   // Selection that was checked, see selectionKey
+	Missing  []string // Packages found neither in the repositories nor in the AUR
+	Err      error
+}
+
+// selectionKey identifies a package selection regardless of its order
+func selectionKey(packages []string) string {
+	sorted := slices.Clone(packages)
+	slices.Sort(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// startInstallationPage checks the selected packages when availability
+// checks are enabled before going to the installation page. Once packages
+// were reported missing, going on again with the same selection installs anyway.
+func (m Model) startInstallationPage() (tea.Model, tea.Cmd) {
+	if !m.options.CheckAvailability {
+		return m.router.Navigate(InstallationPage, m)
+	}
+	if m.checkingAvailability {
+		return m, nil
+	}
+
+	packages := m.getSelectedPackages()
+	selection := selectionKey(packages)
+	if selection == m.checkedPackages {
+		return m.router.Navigate(InstallationPage, m)
+	}
+
+	m.checkingAvailability = true
+	m.missingPackages = nil
+	return m, tea.Batch(
+		m.AddInfoNotification("Checking Packages", fmt.Sprintf("Looking up %d packages in the repositories and the AUR", len(packages))),
+		func() tea.Msg {
+			missing, err := aur.CheckAvailability(packages)
+			return AvailabilityMsg{Packages: selection, Missing: missing, Err: err}
+		},
+	)
+}
+
+// handleAvailability handles the result of the package availability check
+func (m Model) handleAvailability(msg AvailabilityMsg) (tea.Model, tea.Cmd) {
+	m.checkingAvailability = false
+	if m.page != PackageCategoriesPage {
+		return m, nil
+	}
+
+	// The check is best effort, don't hold up the installation on it
+	if msg.Err != nil {
+		m.AddWarningMessage(fmt.Sprintf("Package availability check failed: %v", msg.Err), "packages")
+		notifyCmd := m.AddWarningNotification("Availability Check Failed", "Could not look up the packages, continuing without the check")
+		next, navCmd := m.router.Navigate(InstallationPage, m)
+		return next, tea.Batch(notifyCmd, navCmd)
+	}
+
+	if len(msg.Missing) == 0 {
+		return m.router.Navigate(InstallationPage, m)
+	}
+
+	m.checkedPackages = msg.Packages
+	m.missingPackages = msg.Missing
+	for _, pkg := range msg.Missing {
+		m.AddWarningMessage(fmt.Sprintf("Package not found in the repositories or the AUR: %s", pkg), "packages")
+	}
+	return m, m.AddWarningNotification("Packages Not Found",
+		fmt.Sprintf("%s not found, change the selection or press Right again to install anyway", strings.Join(msg.Missing, ", ")))
+}
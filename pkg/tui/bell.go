@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pulseTicks is how many indeterminate progress ticks the password prompt
+// border stays in one color while pulsing
+const pulseTicks = 5
+
+// alertPasswordPrompt rings the terminal bell and sends a desktop
+// notification when the --bell option is set, so a user who stepped away
+// knows the installer is waiting for the sudo password
+func (m Model) alertPasswordPrompt() tea.Cmd {
+	if !m.options.Bell {
+		return nil
+	}
+
+	return func() tea.Msg {
+		fmt.Fprint(os.Stdout, "\a")
+
+		// notify-send isn't installed everywhere, the bell is enough then
+		if path, err := exec.LookPath("notify-send"); err == nil {
+			_ = exec.Command(path, "--app-name=Lunaris Installer", "Lunaris Installer", "Enter your sudo password to continue the installation").Run()
+		}
+		return nil
+	}
+}
+
+// pulseColor returns the password prompt border color, alternating with the
+// indeterminate progress ticks to draw attention to the prompt
+func (m Model) pulseColor() lipgloss.Color {
+	if (m.indeterminatePos/pulseTicks)%2 == 0 {
+		return ui.AccentColor
+	}
+	return ui.DimmedColor
+}
@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// installCheckpoint is the package installation state an interrupted
+// installation is resumed from with --resume
+type installCheckpoint struct {
+	AURHelper          string   `json:"aur_helper"`
+	AURHelperInstalled bool     `json:"aur_helper_installed"`
+	RemainingPackages  []string `json:"remaining_packages"`
+	InstalledPackages  []string `json:"installed_packages"`
+	FailedPackages     []string `json:"failed_packages"`
+	SkippedPackages    []string `json:"skipped_packages"`
+}
+
+// checkpointPath returns where the checkpoint is kept, next to the default summary
+func checkpointPath() string {
+	return filepath.Join(filepath.Dir(defaultSummaryPath()), "checkpoint.json")
+}
+
+// loadCheckpoint reads the checkpoint at path
+func loadCheckpoint(path string) (installCheckpoint, error) {
+	var checkpoint installCheckpoint
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return checkpoint, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if checkpoint.AURHelper == "" {
+		return checkpoint, fmt.Errorf("checkpoint %s has no AUR helper", path)
+	}
+	return checkpoint, nil
+}
+
+// checkpoint returns the current package installation state
+func (m Model) checkpoint() installCheckpoint {
+	checkpoint := installCheckpoint{
+		AURHelperInstalled: m.aurHelperInstalled,
+		RemainingPackages:  append([]string{}, m.packagesToInstall...),
+		InstalledPackages:  append([]string{}, m.installedPackages...),
+		FailedPackages:     append([]string{}, m.failedPackages...),
+		SkippedPackages:    []string{},
+	}
+	if m.aurHelper != nil {
+		checkpoint.AURHelper = m.aurHelper.String()
+	}
+	for pkg, skipped := range m.skippedPackages {
+		if skipped {
+			checkpoint.SkippedPackages = append(checkpoint.SkippedPackages, pkg)
+		}
+	}
+	sort.Strings(checkpoint.SkippedPackages)
+	return checkpoint
+}
+
+// saveCheckpointCmd returns a command that writes the current state to the checkpoint
+func (m Model) saveCheckpointCmd() tea.Cmd {
+	checkpoint := m.checkpoint()
+	path := checkpointPath()
+
+	return func() tea.Msg {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			var data []byte
+			if data, err = json.MarshalIndent(checkpoint, "", "  "); err == nil {
+				err = os.WriteFile(path, append(data, '\n'), 0644)
+			}
+		}
+		if err != nil {
+			return NotificationMsg{
+				Type:    ui.WarningNotification,
+				Title:   "Checkpoint Not Saved",
+				Message: fmt.Sprintf("The installation can't be resumed: %v", err),
+			}
+		}
+		return nil
+	}
+}
+
+// removeCheckpointCmd returns a command that removes the checkpoint once
+// there is nothing left to resume
+func removeCheckpointCmd() tea.Cmd {
+	path := checkpointPath()
+	return func() tea.Msg {
+		os.Remove(path)
+		return nil
+	}
+}
+
+// resumeFromCheckpoint restores the package installation state of the
+// checkpoint, so the installation continues with the remaining packages
+// without building the AUR helper again when it is still installed
+func (m *Model) resumeFromCheckpoint() {
+	checkpoint := m.resume
+	m.aurHelper = m.newAURHelper(checkpoint.AURHelper)
+	m.aurHelperInstalled = checkpoint.AURHelperInstalled && (m.aurHelper.IsPacmanOnly() || m.aurHelper.IsInstalled())
+	m.packagesToInstall = append([]string{}, checkpoint.RemainingPackages...)
+	m.installedPackages = append([]string{}, checkpoint.InstalledPackages...)
+	m.failedPackages = append([]string{}, checkpoint.FailedPackages...)
+	for _, pkg := range checkpoint.SkippedPackages {
+		m.skippedPackages[pkg] = true
+	}
+
+	m.AddInfoMessage(fmt.Sprintf("Resuming with %s, %d packages left", checkpoint.AURHelper, len(m.packagesToInstall)), "resume")
+	if checkpoint.AURHelperInstalled && !m.aurHelperInstalled {
+		m.AddWarningMessage(fmt.Sprintf("%s is no longer installed, installing it again", checkpoint.AURHelper), "resume")
+	}
+}
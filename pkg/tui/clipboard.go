@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/utils"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// outputText returns the contents of the message queue as plain text
+func (m Model) outputText() string {
+	if m.messageQueue == nil {
+		return strings.Join(m.systemMessages, "\n")
+	}
+
+	lines := make([]string, 0, m.messageQueue.Size())
+	for _, msg := range m.messageQueue.Get() {
+		lines = append(lines, msg.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// installCommand returns the shell command that installs packages with the
+// selected AUR helper by hand, listing each package once
+func (m Model) installCommand(packages []string) string {
+	if m.aurHelper == nil || len(packages) == 0 {
+		return ""
+	}
+
+	var unique []string
+	for _, pkg := range packages {
+		if !slices.Contains(unique, pkg) {
+			unique = append(unique, pkg)
+		}
+	}
+	return m.aurHelper.InstallCommand(unique)
+}
+
+// copyCommandToClipboard copies the install command for packages to the
+// system clipboard
+func (m Model) copyCommandToClipboard(packages []string) tea.Cmd {
+	command := m.installCommand(packages)
+	return func() tea.Msg {
+		if command == "" {
+			return NotificationMsg{
+				Type:    ui.WarningNotification,
+				Title:   "Nothing to Copy",
+				Message: "No packages are selected yet",
+			}
+		}
+
+		if err := utils.CopyToClipboard(command); err != nil {
+			return NotificationMsg{
+				Type:    ui.ErrorNotification,
+				Title:   "Copy Failed",
+				Message: err.Error(),
+			}
+		}
+
+		return NotificationMsg{
+			Type:    ui.SuccessNotification,
+			Title:   "Command Copied",
+			Message: "Copied the command installing the packages to the clipboard",
+		}
+	}
+}
+
+// copyOutputToClipboard copies the command output to the system clipboard
+func (m Model) copyOutputToClipboard() tea.Cmd {
+	text := m.outputText()
+	return func() tea.Msg {
+		if text == "" {
+			return NotificationMsg{
+				Type:    ui.WarningNotification,
+				Title:   "Nothing to Copy",
+				Message: "There is no command output yet",
+			}
+		}
+
+		if err := utils.CopyToClipboard(text); err != nil {
+			return NotificationMsg{
+				Type:    ui.ErrorNotification,
+				Title:   "Copy Failed",
+				Message: err.Error(),
+			}
+		}
+
+		return NotificationMsg{
+			Type:    ui.SuccessNotification,
+			Title:   "Output Copied",
+			Message: fmt.Sprintf("Copied %d lines to the clipboard", strings.Count(text, "\n")+1),
+		}
+	}
+}
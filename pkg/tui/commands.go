@@ -2,387 +2,555 @@ package tui
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/manifest"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/system"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/utils"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// startInstallation starts the installation process
+// preparedMsg carries the packages to install once the preparation is done
+type preparedMsg struct {
+	Packages []string
+
+	// OfficialOnly reports that every package is in the official
+	// repositories, so the AUR helper doesn't need to be built
+	OfficialOnly bool
+}
+
+// startInstallation starts the installation process. The packages are
+// prepared by the returned command, showing the preparation phase meanwhile.
 func (m *Model) startInstallation() tea.Cmd {
+	m.installPhase = "Preparation"
+	m.currentStep = "Preparing..."
+	m.errorMessage = ""
+
+	// Measure the free space to report the disk space used on the complete page
+	m.diskBefore = measureDiskUsage()
+
+	// Resume with the remaining packages as they were ordered
+	if m.resume != nil {
+		return msgCmd(preparedMsg{Packages: m.packagesToInstall})
+	}
+
+	// Go back to the AUR helper that was skipped for an earlier selection,
+	// the selection may have changed since
+	if m.skippedAURHelper != "" {
+		m.aurHelper = m.newAURHelper(m.skippedAURHelper)
+		m.aurHelperInstalled = false
+		m.skippedAURHelper = ""
+	}
+
+	packages := m.getSelectedPackages()
+	if len(m.excluded) > 0 {
+		m.AddInfoMessage(fmt.Sprintf("Excluding %s", strings.Join(m.excluded, ", ")), "packages")
+	}
+	sortDependencies := m.options.SortDependencies
+
+	// Building the AUR helper is only worth it for packages from the AUR
+	checkRepos := m.aurHelper != nil && !m.aurHelper.IsPacmanOnly() && !m.aurHelper.IsInstalled()
+
 	return func() tea.Msg {
-		// Initialize the packages to install and calculate total steps
-		m.packagesToInstall = m.getSelectedPackages()
-
-		// Calculate total steps:
-		// - Install AUR helper (1 step)
-		// - Number of packages to install
-		// - Ask for dotfiles installation (1 step)
-		// - Backup directories (1 step if user chooses to backup)
-		// - Clone repository (1 step)
-		// - Create directories and copy files (1 step per directory)
-		m.totalSteps = 1 + len(m.packagesToInstall) + 1 + 1 + 1 + len(config.ConfigDirs)
-		m.installProgress = 0
-
-		// Send initial progress message
-		progressMsg := NewInstallProgressMsg(
-			m.installProgress,
-			m.totalSteps,
-			"Starting installation...",
-			"Preparation",
-			nil,
-		)
+		if sortDependencies {
+			// Queries pacman for the dependencies of every package
+			packages = aur.SortByDependencies(packages)
+		}
 
-		// Request sudo password if needed
-		m.awaitingPassword = true
-		return progressMsg
+		msg := preparedMsg{Packages: packages}
+		if checkRepos {
+			// Build the AUR helper as usual when pacman can't tell
+			msg.OfficialOnly, _ = aur.AllInOfficialRepos(packages)
+		}
+		return msg
+	}
+}
+
+// handlePrepared starts installing the prepared packages, asking for the
+// sudo password first
+func (m Model) handlePrepared(msg preparedMsg) (tea.Model, tea.Cmd) {
+	// The installation page was left while preparing
+	if m.router.CurrentPage() != InstallationPage {
+		return m, nil
+	}
+	m.packagesToInstall = msg.Packages
+	m.queuedPackages = append([]string(nil), msg.Packages...)
+
+	// Install with pacman directly instead of building the AUR helper, the
+	// AUR helper step then counts as done
+	if msg.OfficialOnly {
+		m.AddInfoMessage(fmt.Sprintf("All packages are in the official repositories, installing them with pacman instead of building %s", m.aurHelper.String()), "aur-helper")
+		m.skippedAURHelper = m.aurHelper.String()
+		m.aurHelper = m.newAURHelper(aur.PacmanOnly)
+		m.aurHelperInstalled = true
+	}
+
+	// Calculate total steps:
+	// - Install AUR helper (1 step)
+	// - Download packages (1 step if downloading first)
+	// - Number of packages to install
+	// - Ask for dotfiles installation (1 step)
+	// - Backup directories (1 step if user chooses to backup)
+	// - Clone repository (1 step)
+	// - Create directories and copy files (1 step per directory)
+	m.totalSteps = 1 + len(m.packagesToInstall) + 1 + 1 + 1 + len(config.ConfigDirs)
+	if m.options.DownloadFirst {
+		m.totalSteps++
+	}
+	m.stepWeights = m.installStepWeights()
+	m.installProgress = 0
+	if m.aurHelperInstalled {
+		// Resuming after the AUR helper step, or skipping it
+		m.installProgress = 1
+	}
+	m.startTime = time.Now()
+	m.lastActivity = m.startTime
+
+	// The progress is set here rather than by a progress message, which
+	// could arrive after the password and set it back a step
+	m.currentStep = "Starting installation..."
+
+	// Request sudo password if needed
+	m.awaitingPassword = true
+
+	// Abort instead of hanging when nothing happens for too long
+	if m.options.IdleTimeout > 0 {
+		return m, tea.Batch(m.alertPasswordPrompt(), tickWatchdog())
+	}
+	return m, m.alertPasswordPrompt()
+}
+
+// Progress weights, in rough units of time, of the installation steps
+const (
+	aurHelperBuildWeight = 40 // Building an AUR helper from source takes minutes
+	downloadWeight       = 10
+	packageWeight        = 2
+	cloneWeight          = 5
+	defaultStepWeight    = 1
+)
+
+// installStepWeights returns the progress weight of each of m.totalSteps
+// steps, in the same order as the installation runs them
+func (m *Model) installStepWeights() []int {
+	weights := make([]int, 0, m.totalSteps)
+
+	// AUR helper
+	if m.aurHelper != nil && !m.aurHelper.IsPacmanOnly() && !m.aurHelper.IsInstalled() {
+		weights = append(weights, aurHelperBuildWeight)
+	} else {
+		weights = append(weights, defaultStepWeight)
+	}
+
+	// Downloading all packages up front
+	if m.options.DownloadFirst {
+		weights = append(weights, downloadWeight)
+	}
+
+	// Packages
+	for range m.packagesToInstall {
+		weights = append(weights, packageWeight)
+	}
+
+	// Dotfiles confirmation, backup and clone
+	weights = append(weights, defaultStepWeight, defaultStepWeight, cloneWeight)
+
+	// Remaining steps, such as copying each configuration directory
+	for len(weights) < m.totalSteps {
+		weights = append(weights, defaultStepWeight)
+	}
+
+	return weights
+}
+
+// progressPercentage returns the weighted percentage of completed steps
+func (m Model) progressPercentage() int {
+	if m.totalSteps <= 0 {
+		return 0
+	}
+
+	// Fall back to counting steps when no weights are known
+	if len(m.stepWeights) == 0 {
+		return min(m.installProgress*100/m.totalSteps, 100)
+	}
+
+	total, done := 0, 0
+	for i, weight := range m.stepWeights {
+		total += weight
+		if i < m.installProgress {
+			done += weight
+		}
+	}
+	if total == 0 {
+		return 0
 	}
+	return min(done*100/total, 100)
 }
 
-// continueInstallation continues the installation process
+// continueInstallation continues the installation process. It runs inside
+// Update, so it only decides on the next step and leaves the slow work to
+// the returned command.
 func (m *Model) continueInstallation() tea.Cmd {
-	return func() tea.Msg {
-		// If we have a conflict, handle it
-		if m.hasConflict {
-			switch m.conflictOption {
-			case 0: // Skip
-				m.packagesToInstall = m.packagesToInstall[1:]
-				return m.installNextPackage()()
-			case 1: // Replace
-				// Continue with installation, the package manager will handle the replacement
-				return m.installNextPackage()()
-			case 2: // Cancel
-				m.page = PackageCategoriesPage
-				return nil
-			}
-		}
-
-		// If we're in the dotfiles confirmation phase
-		if m.installPhase == "dotfiles_confirmation" {
-			if m.dotfilesConfirmation {
-				// User wants to install dotfiles
-				m.installPhase = "backup_confirmation"
-				return NewBackupConfirmationMsg()
-			} else {
-				// User doesn't want to install dotfiles, skip to completion
-				return NewCompleteMsg()
-			}
+	// If we have a conflict, handle it
+	if m.hasConflict {
+		switch m.conflictOption {
+		case 0: // Skip
+			m.packagesToInstall = m.packagesToInstall[1:]
+			return m.installNextPackage()
+		case 1: // Replace
+			// Continue with installation, the package manager will handle the replacement
+			return m.installNextPackage()
+		case 2: // Cancel
+			m.page = PackageCategoriesPage
+			return nil
 		}
+	}
 
-		// If we're in the backup confirmation phase
-		if m.installPhase == "backup_confirmation" {
-			if m.backupConfirmation {
-				// User wants to backup, proceed with backup
-				return m.backupConfigDirs()()
-			} else {
-				// User doesn't want to backup, proceed with dotfiles installation
-				return m.installDotfiles()()
+	// If we're in the dotfiles confirmation phase
+	if m.installPhase == "dotfiles_confirmation" {
+		if m.dotfilesConfirmation {
+			// Nothing to back up on a fresh system, skip the backup phase
+			if !hasExistingConfig() {
+				m.AddInfoMessage("No existing configuration to back up", "backup")
+				return m.installDotfiles()
 			}
+
+			// User wants to install dotfiles
+			m.installPhase = "backup_confirmation"
+			return msgCmd(NewBackupConfirmationMsg())
+		} else {
+			// User doesn't want to install dotfiles, skip to finishing the installation
+			return m.finishInstallation()
 		}
+	}
 
-		// If we need to install the AUR helper first
-		if !m.aurHelperInstalled {
-			return m.installAURHelper()()
+	// If we're in the backup confirmation phase
+	if m.installPhase == "backup_confirmation" {
+		if m.backupConfirmation {
+			// User wants to backup, proceed with backup
+			return m.backupConfigDirs()
+		} else {
+			// User doesn't want to backup, proceed with dotfiles installation
+			return m.installDotfiles()
 		}
+	}
 
-		// If we have packages to install, install the next one
-		if len(m.packagesToInstall) > 0 {
-			return m.installNextPackage()()
+	// If we're in the overwrite confirmation phase
+	if m.installPhase == "overwrite_confirmation" {
+		if m.overwriteConfirmation {
+			// User allows deleting the existing checkout, proceed with dotfiles installation
+			return m.installDotfiles()
+		} else {
+			// User wants to keep the existing checkout, skip to completion
+			m.AddWarningMessage("Keeping existing HyprLuna directory, dotfiles were not installed", "dotfiles")
+			return m.finishInstallation()
 		}
+	}
+
+	// If we're in the wallpaper selection phase
+	if m.installPhase == "wallpaper_selection" {
+		m.wallpaperHandled = true
+		return m.generateWallpaperColors()
+	}
+
+	// If we're in the services confirmation phase
+	if m.installPhase == "services_confirmation" {
+		m.servicesHandled = true
+		if m.servicesConfirmation {
+			// User wants the services enabled
+			return m.enableServices()
+		} else {
+			// User will enable the services themselves
+			m.AddInfoMessage("Services were not enabled", "services")
+			return msgCmd(NewCompleteMsg())
+		}
+	}
 
-		// If we're done with packages, proceed to ask about dotfiles installation
-		m.installPhase = "dotfiles_confirmation"
-		return NewDotfilesConfirmationMsg()
+	// The dotfiles are installed, finish the installation
+	if m.installationPhase == "finalize" {
+		return m.finishInstallation()
 	}
+
+	// The backup finished, continue with the dotfiles
+	if m.installationPhase == "post-installation" {
+		return m.installDotfiles()
+	}
+
+	// If we need to install the AUR helper first
+	if !m.aurHelperInstalled {
+		return m.installAURHelper()
+	}
+
+	// Download every package before installing any of them
+	if m.options.DownloadFirst && !m.packagesDownloaded {
+		return m.downloadPackages()
+	}
+
+	// If we have packages to install, install the next one
+	if len(m.packagesToInstall) > 0 {
+		return m.installNextPackage()
+	}
+
+	return m.finishPackages()
+}
+
+// finishPackages moves on once every package was handled: to the dotfiles
+// confirmation, or back to the complete page after retrying failed packages
+func (m *Model) finishPackages() tea.Cmd {
+	m.endPhase(nil)
+	if m.retryingFailed {
+		m.retryingFailed = false
+		return msgCmd(NewCompleteMsg())
+	}
+
+	// Proceed to ask about dotfiles installation
+	m.installPhase = "dotfiles_confirmation"
+	return msgCmd(NewDotfilesConfirmationMsg())
 }
 
 // installAURHelper installs the selected AUR helper
 func (m *Model) installAURHelper() tea.Cmd {
-	return func() tea.Msg {
-		// Update progress for starting AUR helper installation
-		m.installProgress++
-		progressMsg := NewInstallProgressMsg(
+	m.beginPhase(phaseAURHelper)
+	m.installProgress++
+
+	// Nothing to install when packages are installed with pacman only
+	if m.aurHelper.IsPacmanOnly() {
+		m.aurHelperInstalled = true
+		m.AddInfoMessage("No AUR helper selected, packages will be installed with pacman", "aur-helper")
+		m.AddWarningMessage("Packages only available from the AUR will be skipped", "aur-helper")
+		m.installPhase = "Package Installation"
+
+		return msgCmd(NewInstallProgressMsg(
 			m.installProgress,
 			m.totalSteps,
-			fmt.Sprintf("Installing AUR helper: %s...", m.aurHelper.Name),
-			"AUR Helper Installation",
+			"Starting package installation...",
+			"Package Installation",
 			nil,
-		)
+		))
+	}
 
-		// Create a channel to send progress updates
-		errorCh := make(chan error, 1)
-		messagesCh := make(chan string, 10) // Buffer for messages
-		doneCh := make(chan bool, 1)
-
-		// Run the installation in a goroutine
-		go func() {
-			// Install the AUR helper
-			messages, err := m.aurHelper.Install()
-
-			// Send messages as they come in
-			for _, msg := range messages {
-				select {
-				case messagesCh <- msg:
-					// Message sent
-				default:
-					// Channel full, just continue
-				}
-			}
+	// Copy what the command needs so it never reads the model
+	helper := m.aurHelper
+	progress, total := m.installProgress, m.totalSteps
 
-			if err != nil {
-				errorCh <- err
-				return
-			}
+	return func() tea.Msg {
+		progressMsg := NewInstallProgressMsg(
+			progress,
+			total,
+			fmt.Sprintf("Installing AUR helper: %s...", helper.String()),
+			"AUR Helper Installation",
+			nil,
+		)
+		progressMsg.Source = "aur-helper"
+		progressMsg.RetryStep = "aur-helper"
 
-			// Signal completion
-			doneCh <- true
-		}()
+		// Each build attempt is killed once it runs longer than the timeout,
+		// so the retries of a slow build aren't cut short here
+		messages, err := helper.Install()
+		progressMsg.Output = messages
+		if err != nil {
+			progressMsg.Error = err
+			return progressMsg
+		}
 
-		// Set up a ticker to update the UI frequently
-		ticker := time.NewTicker(100 * time.Millisecond)
-		defer ticker.Stop()
+		// Report the AUR helper as installed and move to the next phase
+		progressMsg.Output = append(progressMsg.Output, fmt.Sprintf("%s installed successfully", helper.String()))
+		progressMsg.AURHelperInstalled = true
+		progressMsg.CurrentStep = "Starting package installation..."
+		progressMsg.Phase = "Package Installation"
+		return progressMsg
+	}
+}
 
-		// Set up a timeout timer for long operations
-		timeout := time.NewTimer(30 * time.Minute)
-		defer timeout.Stop()
+// downloadPackages downloads the selected packages into the package cache
+func (m *Model) downloadPackages() tea.Cmd {
+	m.beginPhase(phaseDownload)
+	m.installProgress++
 
-		// Wait for installation to complete or for progress updates
-		for {
-			select {
-			case err := <-errorCh:
-				progressMsg.Error = err
-				return progressMsg
+	// Copy what the command needs so it never reads the model
+	helper := m.aurHelper
+	packages := append([]string(nil), m.packagesToInstall...)
+	progress, total := m.installProgress, m.totalSteps
 
-			case message := <-messagesCh:
-				// Add message to message queue and system messages
-				m.AddMessage(message, "aur-helper")
-
-				// Update the current step with the message
-				m.currentStep = message
-
-				// Return a progress message to update the UI
-				return NewInstallProgressMsg(
-					m.installProgress,
-					m.totalSteps,
-					message,
-					"AUR Helper Installation",
-					nil,
-				)
-
-			case <-doneCh:
-				// Mark AUR helper as installed
-				m.aurHelperInstalled = true
-
-				// Add final success message
-				m.AddSuccessMessage(fmt.Sprintf("%s installed successfully", m.aurHelper.Name), "aur-helper")
-
-				// Update the phase to Package Installation
-				m.installPhase = "Package Installation"
-
-				// Send a progress update to show we're moving to the next phase
-				return NewInstallProgressMsg(
-					m.installProgress,
-					m.totalSteps,
-					"Starting package installation...",
-					"Package Installation",
-					nil,
-				)
-
-			case <-ticker.C:
-				// Check if installation is complete
-				if m.aurHelper.IsInstalled() {
-					// Mark AUR helper as installed
-					m.aurHelperInstalled = true
-
-					// Add final success message
-					m.AddSuccessMessage(fmt.Sprintf("%s installed successfully", m.aurHelper.Name), "aur-helper")
-
-					// Update the phase to Package Installation
-					m.installPhase = "Package Installation"
-
-					// Create progress message
-					progressMsg := NewInstallProgressMsg(
-						m.installProgress,
-						m.totalSteps,
-						"Starting package installation...",
-						"Package Installation",
-						nil,
-					)
-
-					// Show notification and return progress message
-					return tea.Batch(
-						m.AddSuccessNotification("AUR Helper Installed", fmt.Sprintf("%s has been installed successfully", m.aurHelper.Name)),
-						func() tea.Msg { return progressMsg },
-					)()
-				}
+	return func() tea.Msg {
+		progressMsg := NewInstallProgressMsg(
+			progress,
+			total,
+			"Downloading the official repository packages, AUR packages are downloaded when they are built...",
+			"Package Download",
+			nil,
+		)
+		progressMsg.Source = "package-download"
+		progressMsg.RetryStep = "download"
 
-				// Return a progress message to update the UI
-				return NewInstallProgressMsg(
-					m.installProgress,
-					m.totalSteps,
-					m.currentStep,
-					"AUR Helper Installation",
-					nil,
-				)
-
-			case <-timeout.C:
-				// If no updates for 30 minutes, assume installation failed
-				progressMsg.Error = fmt.Errorf("installation timed out after 30 minutes")
-				return progressMsg
-			}
+		messages, err := helper.DownloadPackages(packages)
+		progressMsg.Output = messages
+		progressMsg.DownloadedBytes, progressMsg.DownloadTotalBytes = helper.DownloadProgress()
+		if err != nil {
+			progressMsg.Error = fmt.Errorf("failed to download packages: %w", err)
+			return progressMsg
 		}
+
+		progressMsg.PackagesDownloaded = true
+		progressMsg.CurrentStep = "Starting package installation..."
+		progressMsg.Phase = "Package Installation"
+		return progressMsg
 	}
 }
 
 // installNextPackage installs the next package
 func (m *Model) installNextPackage() tea.Cmd {
-	return func() tea.Msg {
-		if len(m.packagesToInstall) == 0 {
-			// If we're done with packages, proceed to ask about dotfiles installation
-			m.installPhase = "dotfiles_confirmation"
-			return NewDotfilesConfirmationMsg()
-		}
+	if len(m.packagesToInstall) == 0 {
+		return m.finishPackages()
+	}
 
-		// Get the next package
-		pkg := m.packagesToInstall[0]
-		m.packagesToInstall = m.packagesToInstall[1:]
+	m.beginPhase(phasePackages)
 
-		// Update progress
-		m.installProgress++
+	// Get the next package
+	pkg := m.packagesToInstall[0]
+	m.packagesToInstall = m.packagesToInstall[1:]
+
+	// Update progress
+	m.installProgress++
+	m.currentCategory = m.categoryProgress(pkg)
+
+	// Copy what the command needs so it never reads the model
+	helper := m.aurHelper
+	progress, total := m.installProgress, m.totalSteps
+	categoryProgress := m.currentCategory
+	strict := m.options.Strict
+
+	return func() tea.Msg {
 		progressMsg := NewInstallProgressMsg(
-			m.installProgress,
-			m.totalSteps,
+			progress,
+			total,
 			fmt.Sprintf("Installing %s...", pkg),
 			"Package Installation",
 			nil,
 		)
+		progressMsg.Source = "package-install"
+		progressMsg.CategoryProgress = categoryProgress
+
+		// Skip AUR-only packages when installing with pacman only
+		if helper.IsPacmanOnly() && !aur.IsInOfficialRepos(pkg) {
+			progressMsg.SkippedPackage = pkg
+			return progressMsg
+		}
 
 		// Install the package
-		messages, err := m.aurHelper.InstallPackages([]string{pkg})
+		messages, err := helper.InstallPackages([]string{pkg})
+		progressMsg.Output = messages
 
-		// Add messages to message queue and system messages
-		if len(messages) > 0 {
-			// Add each message to the message queue
-			for _, msg := range messages {
-				m.AddMessage(msg, "package-install")
-			}
+		// Update the aggregate download progress
+		progressMsg.DownloadedBytes, progressMsg.DownloadTotalBytes = helper.DownloadProgress()
 
-			// Update the current step with the last message
-			if len(messages) > 0 {
-				m.currentStep = messages[len(messages)-1]
+		if err != nil {
+			// Let the user resolve conflicts with installed packages
+			var conflict *aur.ConflictError
+			if errors.As(err, &conflict) {
+				conflictMsg := NewConflictMsg(err.Error())
+				conflictMsg.ConflictPackage = pkg
+				conflictMsg.Output = messages
+				conflictMsg.Source = progressMsg.Source
+				return conflictMsg
 			}
-		}
 
-		if err != nil {
-			// Check if it's a conflict error
-			if strings.Contains(err.Error(), "conflict") {
-				// Extract the package name from the conflict message
-				conflictPkg := pkg
-				m.conflictPackage = conflictPkg
-
-				// If we've chosen to replace all packages, automatically replace
-				if m.replaceAllPackages {
-					// Add a message about automatically replacing
-					m.AddInfoMessage(fmt.Sprintf("Automatically replacing conflicting package: %s", conflictPkg), "conflict-resolution")
-
-					// Continue with installation
-					time.Sleep(500 * time.Millisecond) // Small delay for UI
-					return m.installNextPackage()()
-				}
+			progressMsg.FailedPackage = pkg
 
-				// Otherwise, show conflict resolution dialog
-				m.hasConflict = true
-				m.conflictOption = 0 // Default to Skip
-				return NewConflictMsg(err.Error())
+			// Collect the failure and move on unless every package must be installed
+			if !strict {
+				warning := fmt.Sprintf("Warning: failed to install %s, continuing without it: %v", pkg, err)
+				if errors.Is(err, aur.ErrPackageNotFound) {
+					warning = fmt.Sprintf("Warning: %s was not found in the repositories or the AUR, continuing without it", pkg)
+				}
+				progressMsg.Output = append(progressMsg.Output, warning)
+				return progressMsg
 			}
 
+			progressMsg.RetryStep = "package"
 			progressMsg.Error = err
 			return progressMsg
 		}
 
-		// If there are more packages, continue installation
-		if len(m.packagesToInstall) > 0 {
-			time.Sleep(500 * time.Millisecond) // Small delay for UI
-			return m.installNextPackage()()
-		}
+		progressMsg.InstalledPackage = pkg
+		return progressMsg
+	}
+}
 
-		// If we're done with packages, proceed to ask about dotfiles installation
-		m.installPhase = "dotfiles_confirmation"
-		return NewDotfilesConfirmationMsg()
+// hasExistingConfig reports whether any of the backup directories exist
+func hasExistingConfig() bool {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		// Let the backup prompt handle it
+		return true
+	}
+
+	for _, dir := range config.BackupDirs {
+		if _, err := os.Stat(filepath.Join(homeDir, dir)); err == nil {
+			return true
+		}
 	}
+	return false
 }
 
 // backupConfigDirs backs up the user's .config and .local directories
 func (m *Model) backupConfigDirs() tea.Cmd {
+	m.beginPhase(phaseBackup)
+	m.installProgress++
+	progress, total := m.installProgress, m.totalSteps
+	backupName := config.BackupName(m.options.BackupLabel, time.Now())
+
 	return func() tea.Msg {
-		// Update progress
-		m.installProgress++
 		progressMsg := NewInstallProgressMsg(
-			m.installProgress,
-			m.totalSteps,
+			progress,
+			total,
 			"Backing up configuration directories...",
 			"Backup",
 			nil,
 		)
+		progressMsg.Source = "backup"
 
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			progressMsg.Error = fmt.Errorf("failed to get home directory: %w", err)
+		// Collect progress updates and hand them back to the UI
+		output := &installOutput{}
+		fail := func(err error) tea.Msg {
+			progressMsg.Error = err
+			progressMsg.Output = output.Lines()
 			return progressMsg
 		}
 
-		// Create the backup directory
-		backupDir := filepath.Join(homeDir, "HyprLuna-User-Bak")
-		backupMsg := fmt.Sprintf("Creating backup directory: %s", backupDir)
-		m.AddInfoMessage(backupMsg, "backup")
-		m.currentStep = backupMsg
-
-		// Send an update to the UI
-		progressMsg = NewInstallProgressMsg(
-			m.installProgress,
-			m.totalSteps,
-			backupMsg,
-			"Backup",
-			nil,
-		)
-
-		// Create a channel to send progress updates with a small buffer
-		updateCh := make(chan string, 5)
-
-		// Create a goroutine to process updates and send them to the UI
-		go func() {
-			for msg := range updateCh {
-				// Add message to message queue and system messages
-				m.AddMessage(msg, "backup")
-				m.currentStep = msg
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fail(fmt.Errorf("failed to get home directory: %w", err))
+		}
 
-				// Sleep briefly to allow UI updates to be processed
-				time.Sleep(100 * time.Millisecond)
-			}
-		}()
+		// Create the backup directory, kept apart from the backups of earlier runs
+		backupDir := filepath.Join(homeDir, config.BackupDir, backupName)
+		output.Add(fmt.Sprintf("Creating backup directory: %s", backupDir))
 
 		err = os.MkdirAll(backupDir, 0755)
 		if err != nil {
-			progressMsg.Error = fmt.Errorf("failed to create backup directory: %w", err)
-			close(updateCh)
-			return progressMsg
+			return fail(fmt.Errorf("failed to create backup directory: %w", err))
 		}
 
 		// Directories to backup
-		dirsToBackup := []struct {
+		type backupEntry struct {
 			source      string
 			destination string
 			exists      bool
-		}{
-			{".config", ".config", false},
-			{".local", ".local", false},
-			{".ags", ".ags", false},
+		}
+		dirsToBackup := make([]backupEntry, 0, len(config.BackupDirs))
+		for _, dir := range config.BackupDirs {
+			dirsToBackup = append(dirsToBackup, backupEntry{source: dir, destination: dir})
 		}
 
 		// Check which directories exist
@@ -390,9 +558,9 @@ func (m *Model) backupConfigDirs() tea.Cmd {
 			sourceDir := filepath.Join(homeDir, dir.source)
 			if _, err := os.Stat(sourceDir); err == nil {
 				dirsToBackup[i].exists = true
-				updateCh <- fmt.Sprintf("Found directory to backup: %s", dir.source)
+				output.Add(fmt.Sprintf("Found directory to backup: %s", dir.source))
 			} else {
-				updateCh <- fmt.Sprintf("Directory does not exist, will skip: %s", dir.source)
+				output.Add(fmt.Sprintf("Directory does not exist, will skip: %s", dir.source))
 			}
 		}
 
@@ -405,186 +573,154 @@ func (m *Model) backupConfigDirs() tea.Cmd {
 			sourceDir := filepath.Join(homeDir, dir.source)
 			destDir := filepath.Join(backupDir, dir.destination)
 
-			backupMsg := fmt.Sprintf("Backing up %s to %s", dir.source, dir.destination)
-			updateCh <- backupMsg
+			output.Add(fmt.Sprintf("Backing up %s to %s", dir.source, dir.destination))
 
 			// Create parent directories if needed
 			err = os.MkdirAll(filepath.Dir(destDir), 0755)
 			if err != nil {
-				progressMsg.Error = fmt.Errorf("failed to create backup directory for %s: %w", dir.source, err)
-				close(updateCh)
-				return progressMsg
+				return fail(fmt.Errorf("failed to create backup directory for %s: %w", dir.source, err))
 			}
 
-			// Use rsync-like approach for copying to reduce memory usage
-			// This copies files one by one instead of loading entire directories into memory
-			err = utils.CopyDirWithLowMemory(sourceDir, destDir)
+			// Copy with rsync when available, otherwise file by file to reduce memory usage
+			err = copyDir(output, sourceDir, destDir)
 			if err != nil {
-				progressMsg.Error = fmt.Errorf("failed to backup %s directory: %w", dir.source, err)
-				close(updateCh)
-				return progressMsg
+				return fail(fmt.Errorf("failed to backup %s directory: %w", dir.source, err))
 			}
 
-			updateCh <- fmt.Sprintf("Successfully backed up %s to %s", dir.source, dir.destination)
+			output.Add(fmt.Sprintf("Successfully backed up %s to %s", dir.source, dir.destination))
 		}
 
-		updateCh <- "Backup completed successfully"
-		close(updateCh)
-
-		// Sleep briefly to allow final updates to be processed
-		time.Sleep(500 * time.Millisecond)
+		output.Add("Backup completed successfully")
 
-		// Update the phase to Post-Installation
-		m.installPhase = "Post-Installation"
-
-		// Send a progress update to show we're moving to the next phase
-		progressMsg = NewInstallProgressMsg(
-			m.installProgress,
-			m.totalSteps,
-			"Starting dotfiles installation...",
-			"Post-Installation",
-			nil,
-		)
-
-		// Proceed with dotfiles installation
-		return m.installDotfiles()()
+		// Report the backup location and move on to the dotfiles
+		progressMsg.Output = output.Lines()
+		progressMsg.BackupDir = backupDir
+		progressMsg.CurrentStep = "Starting dotfiles installation..."
+		progressMsg.Phase = "Post-Installation"
+		return progressMsg
 	}
 }
 
 // installDotfiles installs the dotfiles
 func (m *Model) installDotfiles() tea.Cmd {
+	noReclone := m.options.NoReclone
+	useExisting := m.options.UseExisting
+	fullClone := m.options.FullClone
+	cleanRepo := m.options.CleanRepo
+	repo := m.options.Repo
+	gitToken := m.options.GitToken
+
+	// Ask before deleting an existing checkout unless explicitly allowed
+	if !m.overwriteConfirmation && !useExisting {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			hyprLunaDir := filepath.Join(homeDir, "HyprLuna")
+			if _, err := os.Stat(hyprLunaDir); err == nil && !(noReclone && system.IsGitCheckoutOf(hyprLunaDir, repo)) {
+				m.endPhase(nil)
+				m.installPhase = "overwrite_confirmation"
+				return msgCmd(NewOverwriteConfirmationMsg())
+			}
+		}
+	}
+
+	m.beginPhase(phaseDotfiles)
+	m.installProgress++
+	progress, total := m.installProgress, m.totalSteps
+
 	return func() tea.Msg {
-		// Update progress
-		m.installProgress++
 		progressMsg := NewInstallProgressMsg(
-			m.installProgress,
-			m.totalSteps,
+			progress,
+			total,
 			"Installing dotfiles...",
 			"Post-Installation",
 			nil,
 		)
+		progressMsg.Source = "dotfiles"
+		progressMsg.RetryStep = "dotfiles"
 
-		// Create a channel to send progress updates with a small buffer
-		updateCh := make(chan string, 5)
-
-		// Create a goroutine to process updates and send them to the UI
-		go func() {
-			for msg := range updateCh {
-				// Add message to message queue and system messages
-				m.AddMessage(msg, "dotfiles")
-				m.currentStep = msg
-
-				// Sleep briefly to allow UI updates to be processed
-				time.Sleep(100 * time.Millisecond)
-			}
-		}()
+		// Collect progress updates and hand them back to the UI
+		output := &installOutput{}
+		fail := func(err error) tea.Msg {
+			progressMsg.Error = err
+			progressMsg.Output = output.Lines()
+			return progressMsg
+		}
 
-		updateCh <- "Starting dotfiles installation..."
+		output.Add("Starting dotfiles installation...")
 
 		// Get home directory
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			progressMsg.Error = fmt.Errorf("failed to get home directory: %w", err)
-			close(updateCh)
-			return progressMsg
+			return fail(fmt.Errorf("failed to get home directory: %w", err))
 		}
 
-		// Clone the repository to ~/HyprLuna
-		updateCh <- fmt.Sprintf("Cloning configuration repository from %s", config.ConfigRepo)
-
-		// Create the HyprLuna directory in the user's home directory
-		hyprLunaDir := filepath.Join(homeDir, "HyprLuna")
-
-		// Remove the directory if it already exists
-		if _, err := os.Stat(hyprLunaDir); err == nil {
-			updateCh <- fmt.Sprintf("Removing existing directory: %s", hyprLunaDir)
-			err = os.RemoveAll(hyprLunaDir)
-			if err != nil {
-				progressMsg.Error = fmt.Errorf("failed to remove existing HyprLuna directory: %w", err)
-				close(updateCh)
-				return progressMsg
+		// Git isn't needed to install an existing checkout as it is
+		if !useExisting {
+			// Make sure git is available before touching the existing checkout
+			if err := system.RequireGit(); err != nil {
+				return fail(err)
 			}
-		}
 
-		// Clone the repository with output capture but use a pipe to reduce memory usage
-		cmd := exec.Command("git", "clone", "--depth=1", "--single-branch", config.ConfigRepo, hyprLunaDir)
-
-		// Set up pipes for stdout and stderr
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			progressMsg.Error = fmt.Errorf("failed to create stdout pipe: %w", err)
-			close(updateCh)
-			return progressMsg
-		}
-
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			progressMsg.Error = fmt.Errorf("failed to create stderr pipe: %w", err)
-			close(updateCh)
-			return progressMsg
-		}
-
-		updateCh <- "Running git clone command..."
-
-		// Start the command
-		if err := cmd.Start(); err != nil {
-			progressMsg.Error = fmt.Errorf("failed to start git clone: %w", err)
-			close(updateCh)
-			return progressMsg
+			// Fail fast instead of waiting for git to time out without a network
+			if address := system.RemoteAddress(repo); address != "" {
+				if err := system.CheckNetwork(address); err != nil {
+					return fail(err)
+				}
+			}
 		}
 
-		// Process output in a separate goroutine
-		outputDone := make(chan struct{})
-		go func() {
-			defer close(outputDone)
-
-			// Use scanners to read output line by line
-			stdoutScanner := bufio.NewScanner(stdout)
-			stderrScanner := bufio.NewScanner(stderr)
+		// Create the HyprLuna directory in the user's home directory
+		hyprLunaDir := filepath.Join(homeDir, "HyprLuna")
 
-			// Process stdout
-			go func() {
-				for stdoutScanner.Scan() {
-					line := stdoutScanner.Text()
-					if line != "" {
-						updateCh <- line
-					}
-				}
-			}()
+		if useExisting {
+			// Install the checkout as it is, including local changes
+			if err := checkConfigRepo(hyprLunaDir); err != nil {
+				return fail(err)
+			}
+			output.Add(fmt.Sprintf("Using the existing configuration in %s", hyprLunaDir))
+		} else if noReclone && system.IsGitCheckoutOf(hyprLunaDir, repo) {
+			// Update the existing checkout instead of cloning it again
+			output.Add(fmt.Sprintf("Updating existing checkout in %s", hyprLunaDir))
+			if err := runGit(output, repo, gitToken, "-C", hyprLunaDir, "pull", "--ff-only"); err != nil {
+				return fail(fmt.Errorf("git pull failed: %v", err))
+			}
 
-			// Process stderr
-			for stderrScanner.Scan() {
-				line := stderrScanner.Text()
-				if line != "" {
-					updateCh <- line
+			output.Add("Repository updated successfully")
+		} else {
+			// Clone the repository to ~/HyprLuna
+			output.Add(fmt.Sprintf("Cloning configuration repository from %s", repo))
+
+			// Remove the directory if it already exists
+			if _, err := os.Stat(hyprLunaDir); err == nil {
+				output.Add(fmt.Sprintf("Removing existing directory: %s", hyprLunaDir))
+				err = os.RemoveAll(hyprLunaDir)
+				if err != nil {
+					return fail(fmt.Errorf("failed to remove existing HyprLuna directory: %w", err))
 				}
 			}
-		}()
 
-		// Wait for the command to complete
-		err = cmd.Wait()
+			// Only the latest commit is needed to copy the configuration
+			args := []string{"clone", "--progress"}
+			if !fullClone {
+				args = append(args, "--depth=1", "--single-branch")
+			}
+			args = append(args, repo, hyprLunaDir)
 
-		// Wait for output processing to complete
-		<-outputDone
+			output.Add("Running git clone command...")
+			if err := runGit(output, repo, gitToken, args...); err != nil {
+				return fail(fmt.Errorf("git clone failed: %v", err))
+			}
 
-		if err != nil {
-			progressMsg.Error = fmt.Errorf("git clone failed: %v", err)
-			close(updateCh)
-			return progressMsg
-		}
+			// Check if the clone was successful by verifying directory contents
+			files, err := os.ReadDir(hyprLunaDir)
+			if err != nil || len(files) == 0 {
+				return fail(fmt.Errorf("repository cloned but appears to be empty"))
+			}
 
-		// Check if the clone was successful by verifying directory contents
-		files, err := os.ReadDir(hyprLunaDir)
-		if err != nil || len(files) == 0 {
-			progressMsg.Error = fmt.Errorf("repository cloned but appears to be empty")
-			close(updateCh)
-			return progressMsg
+			output.Add("Repository cloned successfully")
 		}
 
-		updateCh <- "Repository cloned successfully"
-
 		// Get list of directories to copy
-		updateCh <- "Checking which configuration directories exist in the repository..."
+		output.Add("Checking which configuration directories exist in the repository...")
 
 		// Check which directories exist in the repository
 		existingDirs := []string{}
@@ -592,56 +728,85 @@ func (m *Model) installDotfiles() tea.Cmd {
 			sourceDir := filepath.Join(hyprLunaDir, configDir)
 			if _, err := os.Stat(sourceDir); !os.IsNotExist(err) {
 				existingDirs = append(existingDirs, configDir)
-				updateCh <- fmt.Sprintf("Found directory in repository: %s", configDir)
+				output.Add(fmt.Sprintf("Found directory in repository: %s", configDir))
 			} else {
-				updateCh <- fmt.Sprintf("Directory not found in repository, will skip: %s", configDir)
+				output.Add(fmt.Sprintf("Directory not found in repository, will skip: %s", configDir))
 			}
 		}
 
+		// Make sure there is enough free space before copying anything
+		output.Add("Checking available disk space...")
+		var requiredSpace uint64
+		for _, configDir := range existingDirs {
+			size, err := utils.DirSize(filepath.Join(hyprLunaDir, configDir))
+			if err != nil {
+				return fail(err)
+			}
+			requiredSpace += size
+		}
+
+		freeSpace, err := utils.FreeSpace(homeDir)
+		if err != nil {
+			return fail(err)
+		}
+
+		if freeSpace < requiredSpace {
+			return fail(fmt.Errorf("not enough disk space to install dotfiles: %s required, %s available in %s",
+				utils.FormatBytes(requiredSpace), utils.FormatBytes(freeSpace), homeDir))
+		}
+
+		output.Add(fmt.Sprintf("Disk space OK: %s required, %s available", utils.FormatBytes(requiredSpace), utils.FormatBytes(freeSpace)))
+
 		// Copy configuration files from the cloned repository to the user's home directory
 		for _, configDir := range existingDirs {
-			updateCh <- fmt.Sprintf("Copying %s...", configDir)
+			output.Add(fmt.Sprintf("Copying %s...", configDir))
 
 			// Create the target directory
 			targetDir := filepath.Join(homeDir, configDir)
 			err := os.MkdirAll(filepath.Dir(targetDir), 0755)
 			if err != nil {
-				progressMsg.Error = fmt.Errorf("failed to create directory %s: %w", targetDir, err)
-				close(updateCh)
-				return progressMsg
+				return fail(fmt.Errorf("failed to create directory %s: %w", targetDir, err))
 			}
 
-			// Copy the configuration files using the low memory copy function
+			// Copy the configuration files with rsync or the low memory copy function
 			sourceDir := filepath.Join(hyprLunaDir, configDir)
-			err = utils.CopyDirWithLowMemory(sourceDir, targetDir)
+			err = copyDir(output, sourceDir, targetDir)
 			if err != nil {
-				progressMsg.Error = fmt.Errorf("failed to copy files to %s: %w", targetDir, err)
-				close(updateCh)
-				return progressMsg
+				return fail(fmt.Errorf("failed to copy files to %s: %w", targetDir, err))
 			}
 
-			updateCh <- fmt.Sprintf("Successfully copied %s", configDir)
+			output.Add(fmt.Sprintf("Successfully copied %s", configDir))
 
 			// Update progress for each directory copied
-			m.installProgress++
-			progressMsg = NewInstallProgressMsg(
-				m.installProgress,
-				m.totalSteps,
-				fmt.Sprintf("Copied %s", configDir),
-				"Post-Installation",
-				nil,
-			)
+			progressMsg.Progress++
+		}
+
+		// Record the installed files so they can be uninstalled later
+		var installedFiles []string
+		for _, configDir := range existingDirs {
+			files, err := manifest.Files(hyprLunaDir, configDir)
+			if err != nil {
+				return fail(err)
+			}
+			installedFiles = append(installedFiles, files...)
+		}
+		if manifestPath := manifest.DefaultPath(); manifestPath != "" {
+			if err := manifest.Write(manifestPath, installedFiles); err != nil {
+				output.Add(fmt.Sprintf("Warning: %v", err))
+			} else {
+				output.Add(fmt.Sprintf("Recorded %d installed files in %s", len(installedFiles), manifestPath))
+			}
 		}
 
 		// Make scripts executable
-		updateCh <- "Making scripts executable..."
+		output.Add("Making scripts executable...")
 
 		// Make hypr scripts executable
 		hyprScriptsDir := filepath.Join(homeDir, ".config", "hypr", "scripts")
 		if _, err := os.Stat(hyprScriptsDir); err == nil {
 			chmodCmd := exec.Command("sh", "-c", fmt.Sprintf("chmod +x %s/*", hyprScriptsDir))
 			chmodCmd.Run()
-			updateCh <- "Made hypr scripts executable"
+			output.Add("Made hypr scripts executable")
 		}
 
 		// Make ags scripts executable
@@ -649,38 +814,324 @@ func (m *Model) installDotfiles() tea.Cmd {
 		if _, err := os.Stat(agsScriptsDir); err == nil {
 			chmodCmd := exec.Command("sh", "-c", fmt.Sprintf("chmod +x %s/*", agsScriptsDir))
 			chmodCmd.Run()
-			updateCh <- "Made ags scripts executable"
+			output.Add("Made ags scripts executable")
 		}
 
-		// Run wallpaper script
-		wallpaperScript := filepath.Join(homeDir, ".config", "ags", "scripts", "color_generation", "wallpapers.sh")
+		// Let the user pick a wallpaper to generate colors from, or use a random one
+		wallpaperScript := wallpaperScriptPath(homeDir)
 		if _, err := os.Stat(wallpaperScript); err == nil {
-			wallpaperCmd := exec.Command("sh", wallpaperScript, "-r")
-			wallpaperCmd.Run()
-			updateCh <- "Generated wallpaper colors"
+			if wallpapers := findWallpapers(wallpaperDir(homeDir)); len(wallpapers) > 0 {
+				progressMsg.WallpaperScript = wallpaperScript
+				progressMsg.Wallpapers = wallpapers
+			} else {
+				wallpaperCmd := exec.Command("sh", wallpaperScript, "-r")
+				wallpaperCmd.Run()
+				output.Add("Generated wallpaper colors")
+			}
+		}
+
+		// Show the instructions that came with the dotfiles on the complete page
+		if instructions, path := readInstructions(hyprLunaDir); len(instructions) > 0 {
+			progressMsg.Instructions = instructions
+			output.Add(fmt.Sprintf("Read post-install instructions from %s", path))
+		}
+
+		// Remove the cloned repository now that everything has been copied,
+		// but never a checkout the installer didn't clone
+		if cleanRepo && useExisting {
+			output.Add(fmt.Sprintf("Keeping %s, it was not cloned by the installer", hyprLunaDir))
+		} else if cleanRepo {
+			size, _ := utils.DirSize(hyprLunaDir)
+			if err := os.RemoveAll(hyprLunaDir); err != nil {
+				output.Add(fmt.Sprintf("Failed to remove %s: %v", hyprLunaDir, err))
+			} else {
+				output.Add(fmt.Sprintf("Removed %s, freeing %s", hyprLunaDir, utils.FormatBytes(size)))
+				progressMsg.CleanupFreed = size
+			}
 		}
 
 		// Add final system message
-		updateCh <- "Dotfiles installation complete!"
-		close(updateCh)
+		output.Add("Dotfiles installation complete!")
 
-		// Sleep briefly to allow final updates to be processed
-		time.Sleep(500 * time.Millisecond)
+		// Dotfiles installed, move on to finishing the installation
+		progressMsg.Output = output.Lines()
+		progressMsg.CurrentStep = "Finishing installation..."
+		progressMsg.DotfilesInstalled = true
+		return progressMsg
+	}
+}
 
-		// Installation complete
-		return NewCompleteMsg()
+// checkConfigRepo returns an error unless dir has the directories of the
+// config repository listed in config.RequiredConfigPaths
+func checkConfigRepo(dir string) error {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s does not exist, clone the configuration first or run without --use-existing", dir)
+	}
+
+	var missing []string
+	for _, path := range config.RequiredConfigPaths {
+		if info, err := os.Stat(filepath.Join(dir, path)); err != nil || !info.IsDir() {
+			missing = append(missing, path)
+		}
 	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is missing %s, it doesn't look like a HyprLuna configuration", dir, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// maxInstructionLines limits how much of the instructions file is shown
+const maxInstructionLines = 20
+
+// readInstructions returns the non-empty lines of the first instructions file
+// found in repoDir and its path
+func readInstructions(repoDir string) ([]string, string) {
+	for _, name := range config.InstructionsFiles {
+		path := filepath.Join(repoDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var lines []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > maxInstructionLines {
+			lines = lines[:maxInstructionLines]
+		}
+		return lines, path
+	}
+	return nil, ""
+}
+
+// retryInstallation runs the step that failed because of the network or a timeout again
+func (m *Model) retryInstallation() tea.Cmd {
+	step := m.retryStep
+	m.retryStep = ""
+	m.failedStep = ""
+	m.errorMessage = ""
+
+	// The failed step is counted again when it is retried
+	m.installProgress--
+
+	switch step {
+	case "aur-helper":
+		m.AddInfoMessage("Retrying AUR helper installation...", "aur-helper")
+		return m.installAURHelper()
+	case "dotfiles":
+		m.AddInfoMessage("Retrying dotfiles installation...", "dotfiles")
+		return m.installDotfiles()
+	case "download":
+		m.AddInfoMessage("Retrying package download...", "package-download")
+		return m.downloadPackages()
+	case "package":
+		// Put the failed package back at the front of the queue
+		if n := len(m.failedPackages); n > 0 {
+			pkg := m.failedPackages[n-1]
+			m.failedPackages = m.failedPackages[:n-1]
+			m.packagesToInstall = append([]string{pkg}, m.packagesToInstall...)
+			m.AddInfoMessage(fmt.Sprintf("Retrying %s...", pkg), "package-install")
+			return m.installNextPackage()
+		}
+	}
+	return nil
+}
+
+// retryFailedPackages installs the packages that failed again, without the
+// other steps, and returns to the complete page once they were handled.
+// Packages that fail again are collected anew.
+func (m *Model) retryFailedPackages() tea.Cmd {
+	m.packagesToInstall = m.failedPackages
+	m.failedPackages = make([]string, 0)
+	m.retryingFailed = true
+	m.installationPhase = "packages"
+	m.installPhase = "Package Installation"
+	m.errorMessage = ""
+
+	// Count the progress of the retried packages only
+	m.installProgress = 0
+	m.totalSteps = len(m.packagesToInstall)
+	m.stepWeights = nil
+
+	m.AddInfoMessage(fmt.Sprintf("Retrying %d failed packages...", len(m.packagesToInstall)), "package-install")
+	return m.installNextPackage()
 }
 
-// getSelectedPackages returns a list of selected packages
+// selectedServices returns the systemd units required by the selected options
+func (m *Model) selectedServices() []string {
+	var services []string
+	for _, category := range m.categories {
+		for _, option := range category.Options {
+			for _, selected := range m.selectedOptions[category.Name] {
+				if selected == option.Name {
+					services = append(services, option.Services...)
+				}
+			}
+		}
+	}
+	return services
+}
+
+// finishInstallation asks for a wallpaper and to enable services before completing the installation
+func (m *Model) finishInstallation() tea.Cmd {
+	m.beginPhase(phasePostInstallation)
+	if m.wallpaperScript != "" && !m.wallpaperHandled {
+		m.installPhase = "wallpaper_selection"
+		return msgCmd(NewWallpaperSelectionMsg())
+	}
+	if !m.servicesHandled && len(m.options.Services)+len(m.selectedServices()) > 0 {
+		m.installPhase = "services_confirmation"
+		return msgCmd(NewServicesConfirmationMsg())
+	}
+	return msgCmd(NewCompleteMsg())
+}
+
+// enableServices enables the required services and the selected display
+// managers, adding the HyprLuna session entry when a display manager is selected
+func (m *Model) enableServices() tea.Cmd {
+	requiredServices := append([]string(nil), m.options.Services...)
+	services := m.selectedServices()
+	password := m.aurHelper.GetSudoPassword()
+	progress, total := m.installProgress, m.totalSteps
+
+	return func() tea.Msg {
+		output := &installOutput{}
+
+		// Start the required services right away
+		for _, service := range requiredServices {
+			if err := system.EnableService(service, password, true); err != nil {
+				output.Add(err.Error())
+				continue
+			}
+			output.Add(fmt.Sprintf("Enabled and started %s", service))
+		}
+
+		// Display managers need a session entry to list HyprLuna
+		if len(services) > 0 {
+			if err := system.InstallRootFile(config.SessionFile, config.SessionFileContents, password, 0644); err != nil {
+				output.Add(err.Error())
+			} else {
+				output.Add(fmt.Sprintf("Installed session entry %s", config.SessionFile))
+			}
+		}
+
+		// Display managers take over at the next boot, so they are not started now
+		for _, service := range services {
+			if err := system.EnableService(service, password, false); err != nil {
+				output.Add(err.Error())
+				continue
+			}
+			output.Add(fmt.Sprintf("Enabled %s", service))
+		}
+
+		completeMsg := NewCompleteMsg()
+		completeMsg.Progress = progress
+		completeMsg.Total = total
+		completeMsg.Output = output.Lines()
+		completeMsg.Source = "services"
+		return completeMsg
+	}
+}
+
+// copyDir copies src into dst, preferring rsync and falling back to
+// utils.CopyDirWithLowMemory when rsync is missing or fails
+func copyDir(output *installOutput, src, dst string) error {
+	if utils.RsyncAvailable() {
+		output.Add(fmt.Sprintf("Copying %s with rsync", src))
+		err := utils.CopyDirWithRsync(src, dst, output.Add)
+		if err == nil {
+			return nil
+		}
+		output.Add(fmt.Sprintf("Warning: %v, retrying with the built-in copy", err))
+	} else {
+		output.Add(fmt.Sprintf("rsync not found, copying %s with the built-in copy", src))
+	}
+
+	return utils.CopyDirWithLowMemory(src, dst)
+}
+
+// runGit runs git with the given arguments against remote, authenticated by
+// the user's SSH keys or token, adding its output to output line by line.
+// The output is read through pipes to reduce memory usage. Progress lines
+// are tracked with system.TrackCloneProgress instead of being added.
+func runGit(output *installOutput, remote, token string, args ...string) error {
+	// Authenticate with the user's SSH keys or the token without prompting
+	authArgs, env := system.GitAuth(remote, token)
+	cmd := exec.Command("git", append(authArgs, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	defer system.EndCloneProgress()
+
+	// Set up pipes for stdout and stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	// Start the command
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start git: %w", err)
+	}
+
+	// Read stdout and stderr line by line until both pipes are closed
+	var wg sync.WaitGroup
+	for _, pipe := range []io.Reader{stdout, stderr} {
+		wg.Add(1)
+		go func(pipe io.Reader) {
+			defer wg.Done()
+			scanner := bufio.NewScanner(pipe)
+			scanner.Split(system.ScanProgressLines)
+			for scanner.Scan() {
+				if line := scanner.Text(); line != "" && !system.TrackCloneProgress(line) {
+					output.Add(line)
+				}
+			}
+		}(pipe)
+	}
+
+	// Wait for output processing to complete before waiting for the command
+	wg.Wait()
+	return cmd.Wait()
+}
+
+// packageOrigin records which category and option queued a package
+type packageOrigin struct {
+	Category string
+	Option   string
+	Index    int // 1-based position within the category
+	Total    int // Number of packages queued from the category
+}
+
+// getSelectedPackages returns a list of selected packages and records the
+// category and option each package was queued from in m.packageOrigins
 func (m *Model) getSelectedPackages() []string {
 	var packages []string
+	var origins []packageOrigin
+
+	// Add base packages, except those deselected in advanced mode
+	for _, pkg := range config.BasePackages {
+		if !m.deselectedBasePackages[pkg] {
+			packages = append(packages, pkg)
+			origins = append(origins, packageOrigin{Category: config.BasePackagesCategoryName, Option: pkg})
+		}
+	}
+
+	// Add selected packages from categories, in the installation order
+	for _, categoryName := range m.installOrder() {
+		selectedOptions := m.selectedOptions[categoryName]
 
-	// Add base packages
-	packages = append(packages, config.BasePackages...)
+		// Base packages were already added above
+		if categoryName == config.BasePackagesCategoryName {
+			continue
+		}
 
-	// Add selected packages from categories
-	for categoryName, selectedOptions := range m.selectedOptions {
 		for _, optionName := range selectedOptions {
 			// Find the category
 			for _, category := range m.categories {
@@ -690,6 +1141,9 @@ func (m *Model) getSelectedPackages() []string {
 						if option.Name == optionName {
 							// Add the packages
 							packages = append(packages, option.Packages...)
+							for range option.Packages {
+								origins = append(origins, packageOrigin{Category: categoryName, Option: optionName})
+							}
 							break
 						}
 					}
@@ -699,46 +1153,169 @@ func (m *Model) getSelectedPackages() []string {
 		}
 	}
 
+	// Add extra packages that aren't already selected
+	for _, pkg := range m.extraPackages {
+		if !slices.Contains(packages, pkg) {
+			packages = append(packages, pkg)
+			origins = append(origins, packageOrigin{Category: extraPackagesCategory, Option: pkg})
+		}
+	}
+
+	// Drop malformed names, e.g. from custom categories, before they reach the package manager
+	validPackages := make([]string, 0, len(packages))
+	validOrigins := make([]packageOrigin, 0, len(origins))
+	for i, pkg := range packages {
+		pkg = strings.TrimSpace(pkg)
+		if err := config.ValidatePackageName(pkg); err != nil {
+			m.AddErrorMessage(fmt.Sprintf("Skipping package from %s: %v", origins[i].Option, err), "packages")
+			continue
+		}
+		validPackages = append(validPackages, pkg)
+		validOrigins = append(validOrigins, origins[i])
+	}
+	packages, origins = validPackages, validOrigins
+
+	// Leave out excluded packages, wherever they were queued from
+	m.excluded = nil
+	if len(m.excludedPackages) > 0 {
+		keptPackages := packages[:0:0]
+		keptOrigins := origins[:0:0]
+		for i, pkg := range packages {
+			if slices.Contains(m.excludedPackages, pkg) {
+				if !slices.Contains(m.excluded, pkg) {
+					m.excluded = append(m.excluded, pkg)
+				}
+				continue
+			}
+			keptPackages = append(keptPackages, pkg)
+			keptOrigins = append(keptOrigins, origins[i])
+		}
+		packages, origins = keptPackages, keptOrigins
+	}
+
+	// Number each package within its category
+	totals := make(map[string]int)
+	for _, origin := range origins {
+		totals[origin.Category]++
+	}
+	seen := make(map[string]int)
+	m.packageOrigins = make(map[string]packageOrigin)
+	for i, pkg := range packages {
+		origin := origins[i]
+		seen[origin.Category]++
+		origin.Index = seen[origin.Category]
+		origin.Total = totals[origin.Category]
+		if _, exists := m.packageOrigins[pkg]; !exists {
+			m.packageOrigins[pkg] = origin
+		}
+	}
+
 	return packages
 }
 
+// categoryProgress describes a queued package as "Category: Option (i/n)"
+func (m *Model) categoryProgress(pkg string) string {
+	origin, ok := m.packageOrigins[pkg]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s (%d/%d)", origin.Category, origin.Option, origin.Index, origin.Total)
+}
+
 // handleInstallProgress handles installation progress messages
 func (m *Model) handleInstallProgress(msg InstallProgressMsg) (tea.Model, tea.Cmd) {
+	// Notify once the AUR helper has been installed
+	var notifyCmd tea.Cmd
+	if msg.AURHelperInstalled && !m.aurHelperInstalled {
+		notifyCmd = m.AddSuccessNotification("AUR Helper Installed", fmt.Sprintf("%s has been installed successfully", m.aurHelper.String()))
+	}
+
+	// Apply the output and state reported by the command
+	m.applyInstallOutput(msg)
+
+	// The watchdog already aborted the installation, only keep the output
+	if m.stallErr != nil {
+		return m, nil
+	}
+	m.lastActivity = time.Now()
+
 	if msg.IsComplete {
+		m.endPhase(nil)
 		m.page = CompletePage
-		return m, nil
+		m.diskAfter = measureDiskUsage()
+		next, navCmd := m.router.Navigate(CompletePage, *m)
+		return next, tea.Batch(navCmd, m.writeSummaryCmd(nil), removeCheckpointCmd())
 	}
 
 	if msg.HasConflict {
+		m.conflictPackage = msg.ConflictPackage
+
+		// If we've chosen to replace all packages, automatically replace
+		if m.replaceAllPackages {
+			m.AddInfoMessage(fmt.Sprintf("Automatically replacing conflicting package: %s", msg.ConflictPackage), "conflict-resolution")
+			return m, m.installNextPackage()
+		}
+
+		// Otherwise, show conflict resolution dialog
 		m.hasConflict = true
 		m.conflictMessage = msg.Conflict
+		m.conflictOption = 0 // Default to Skip
 		return m, nil
 	}
 
-	if msg.IsDotfilesConfirmation {
-		m.installPhase = "dotfiles_confirmation"
-		return m, nil
-	}
-
-	if msg.IsBackupConfirmation {
-		m.installPhase = "backup_confirmation"
+	// Prompts set their phase when they are asked, so a prompt that was
+	// answered before its message arrived isn't asked again. --yes answers
+	// them, except deleting an existing checkout, which also needs --force.
+	if phase := msg.promptPhase(); phase != "" {
+		if m.installPhase != phase {
+			return m, nil
+		}
+		if m.options.AssumeYes && phase != "overwrite_confirmation" {
+			return m, m.continueInstallation()
+		}
 		return m, nil
 	}
 
 	if msg.Error != nil {
+		m.endPhase(msg.Error)
 		m.errorMessage = msg.Error.Error()
-		return m, nil
+		m.failedStep = msg.RetryStep
+
+		switch {
+		case errors.Is(msg.Error, system.ErrNoNetwork), errors.Is(msg.Error, aur.ErrTimeout):
+			// Network failures and timeouts, e.g. of a slow mirror, can be
+			// retried once the connection is back. The timed out command has
+			// already exited, so a retry doesn't run alongside it.
+			m.retryStep = msg.RetryStep
+		case errors.Is(msg.Error, aur.ErrSudoRequired):
+			m.errorMessage = "sudo rejected the password or asked for one, check that your user may use sudo: " + m.errorMessage
+		}
+
+		m.failedAction = 0
+		next, navCmd := m.router.Navigate(FailedPage, *m)
+		return next, tea.Batch(navCmd, m.writeSummaryCmd(msg.Error))
 	}
 
 	m.installProgress = msg.Progress
 	m.totalSteps = msg.Total
+	if msg.DownloadTotalBytes > 0 {
+		m.downloadedBytes = msg.DownloadedBytes
+		m.downloadTotal = msg.DownloadTotalBytes
+	}
 	m.currentStep = msg.CurrentStep
 	m.installPhase = msg.Phase
+	m.currentCategory = msg.CategoryProgress
 
 	// If we're awaiting password, don't continue installation yet
 	if m.awaitingPassword {
 		return m, nil
 	}
 
-	return m, m.continueInstallation()
+	// Record the packages left after each package step for --resume
+	var checkpointCmd tea.Cmd
+	if msg.AURHelperInstalled || msg.PackagesDownloaded || msg.Source == "package-install" {
+		checkpointCmd = m.saveCheckpointCmd()
+	}
+
+	return m, tea.Batch(notifyCmd, checkpointCmd, m.continueInstallation())
 }
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testCategories are the categories the package selection tests pick from
+var testCategories = []config.PackageCategory{
+	{
+		Name: "Terminals",
+		Options: []config.PackageOption{
+			{Name: "Alacritty", Packages: []string{"alacritty"}},
+			{Name: "Kitty", Packages: []string{"kitty", "kitty-terminfo"}},
+		},
+	},
+	{
+		Name: "Shells",
+		Options: []config.PackageOption{
+			{Name: "Zsh", Packages: []string{"zsh", "zsh-completions"}},
+			{Name: "Nushell", Packages: []string{"nushell"}},
+		},
+	},
+}
+
+func TestGetSelectedPackages(t *testing.T) {
+	m := Model{
+		categories: testCategories,
+		selectedOptions: map[string][]string{
+			"Terminals": {"Kitty"},
+			"Shells":    {"Zsh", "Xonsh"}, // Xonsh isn't an option of any category
+			"Editors":   {"Neovim"},       // Nor is Editors a category
+		},
+	}
+
+	packages := m.getSelectedPackages()
+
+	for _, pkg := range config.BasePackages {
+		if !slices.Contains(packages, pkg) {
+			t.Errorf("base package %s is missing", pkg)
+		}
+	}
+	for _, pkg := range []string{"kitty", "kitty-terminfo", "zsh", "zsh-completions"} {
+		if !slices.Contains(packages, pkg) {
+			t.Errorf("package %s of a selected option is missing", pkg)
+		}
+	}
+	for _, pkg := range []string{"alacritty", "nushell"} {
+		if slices.Contains(packages, pkg) {
+			t.Errorf("package %s of an unselected option is included", pkg)
+		}
+	}
+
+	// Only the base packages and the four packages of the selected options
+	if want := len(config.BasePackages) + 4; len(packages) != want {
+		t.Errorf("got %d packages, want %d: %v", len(packages), want, packages)
+	}
+}
+
+func TestGetSelectedPackagesWithoutSelection(t *testing.T) {
+	m := Model{
+		categories:      testCategories,
+		selectedOptions: map[string][]string{},
+	}
+
+	packages := m.getSelectedPackages()
+
+	if !slices.Equal(packages, config.BasePackages) {
+		t.Errorf("getSelectedPackages() = %v, want only the base packages %v", packages, config.BasePackages)
+	}
+}
+
+func TestGetSelectedPackagesExcluded(t *testing.T) {
+	m := Model{
+		categories:       testCategories,
+		selectedOptions:  map[string][]string{"Terminals": {"Kitty"}},
+		excludedPackages: []string{config.BasePackages[0], "kitty-terminfo", "nushell"},
+	}
+
+	packages := m.getSelectedPackages()
+
+	for _, pkg := range m.excludedPackages {
+		if slices.Contains(packages, pkg) {
+			t.Errorf("excluded package %s is installed: %v", pkg, packages)
+		}
+	}
+	if !slices.Contains(packages, "kitty") {
+		t.Errorf("kitty is missing although only kitty-terminfo is excluded: %v", packages)
+	}
+	if _, ok := m.packageOrigins["kitty-terminfo"]; ok {
+		t.Error("kitty-terminfo still has an origin after being excluded")
+	}
+
+	// nushell wasn't selected, so it isn't reported as excluded
+	if want := []string{config.BasePackages[0], "kitty-terminfo"}; !slices.Equal(m.excluded, want) {
+		t.Errorf("excluded = %v, want %v", m.excluded, want)
+	}
+}
+
+func TestInstallCommand(t *testing.T) {
+	m := Model{
+		categories:      testCategories,
+		selectedOptions: map[string][]string{"Shells": {"Zsh"}},
+		extraPackages:   []string{"zsh", "htop"}, // zsh is also queued by the Zsh option
+		aurHelper:       aur.NewHelper("yay"),
+	}
+
+	got := m.installCommand(m.getSelectedPackages())
+	if !strings.HasPrefix(got, "yay -S --needed "+config.BasePackages[0]+" ") || !strings.HasSuffix(got, " zsh zsh-completions htop") {
+		t.Errorf("installCommand() = %q, want the base packages, then the selected and extra packages", got)
+	}
+	seen := make(map[string]bool)
+	for _, pkg := range strings.Fields(got) {
+		if seen[pkg] {
+			t.Errorf("installCommand() lists %s more than once", pkg)
+		}
+		seen[pkg] = true
+	}
+
+	m.aurHelper = aur.NewHelper(aur.PacmanOnly)
+	if got := m.installCommand([]string{"htop"}); got != "sudo pacman -S --needed htop" {
+		t.Errorf("installCommand() without an AUR helper = %q, want it run with sudo", got)
+	}
+}
+
+func TestGetSelectedPackagesInstallOrder(t *testing.T) {
+	m := Model{
+		categories: testCategories,
+		selectedOptions: map[string][]string{
+			"Terminals": {"Alacritty"},
+			"Shells":    {"Nushell"},
+		},
+		keyMap: DefaultKeyMap(),
+	}
+
+	// Categories are installed in the order they are listed until moved
+	if packages := m.getSelectedPackages(); slices.Index(packages, "alacritty") > slices.Index(packages, "nushell") {
+		t.Errorf("alacritty is installed after nushell by default: %v", packages)
+	}
+
+	// Install the shells before the terminals
+	m.confirmCursor = 1
+	next, _ := m.handleInstallConfirmation(tea.KeyMsg{Type: tea.KeyShiftUp})
+	m = next.(Model)
+	if want := []string{"Shells", "Terminals"}; !slices.Equal(m.orderedSelection(), want) {
+		t.Fatalf("orderedSelection() = %v, want %v", m.orderedSelection(), want)
+	}
+	if m.confirmCursor != 0 {
+		t.Errorf("confirmCursor = %d, want it to follow the moved category to 0", m.confirmCursor)
+	}
+
+	packages := m.getSelectedPackages()
+	if slices.Index(packages, "nushell") > slices.Index(packages, "alacritty") {
+		t.Errorf("nushell is installed after alacritty once the shells were moved up: %v", packages)
+	}
+	if !slices.Equal(packages[:len(config.BasePackages)], config.BasePackages) {
+		t.Errorf("base packages aren't installed first: %v", packages)
+	}
+}
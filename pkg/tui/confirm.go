@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// confirmInstall asks for confirmation of the number of packages before
+// going to the installation page, unless --yes was given
+func (m Model) confirmInstall() (tea.Model, tea.Cmd) {
+	if m.options.AssumeYes {
+		return m.startInstallationPage()
+	}
+
+	// The same package can be queued by several options
+	packages := m.getSelectedPackages()
+	slices.Sort(packages)
+	m.confirmPackageCount = len(slices.Compact(packages))
+	m.confirmCursor = 0
+	m.confirmingInstall = true
+	return m, nil
+}
+
+// installOrder returns the names of the categories in the order their
+// packages are installed: the order they were moved into, followed by the
+// remaining categories as they are listed
+func (m Model) installOrder() []string {
+	order := slices.Clone(m.categoryOrder)
+	for _, category := range m.categories {
+		if !slices.Contains(order, category.Name) {
+			order = append(order, category.Name)
+		}
+	}
+	return order
+}
+
+// orderedSelection returns the categories with selected options in the
+// installation order, without the base packages, which are always installed first
+func (m Model) orderedSelection() []string {
+	var selected []string
+	for _, name := range m.installOrder() {
+		if name != config.BasePackagesCategoryName && len(m.selectedOptions[name]) > 0 {
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
+
+// moveCategory moves the category at the confirmation cursor by delta places
+// among the selected categories, and the cursor with it
+func (m *Model) moveCategory(delta int) {
+	selected := m.orderedSelection()
+	from, to := m.confirmCursor, m.confirmCursor+delta
+	if from < 0 || from >= len(selected) || to < 0 || to >= len(selected) {
+		return
+	}
+
+	order := m.installOrder()
+	i, j := slices.Index(order, selected[from]), slices.Index(order, selected[to])
+	order[i], order[j] = order[j], order[i]
+	m.categoryOrder = order
+	m.confirmCursor = to
+}
+
+// handleInstallConfirmation handles the package count confirmation
+func (m Model) handleInstallConfirmation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keyMap.Enter), key.Matches(msg, m.keyMap.Right), msg.String() == "y":
+		m.confirmingInstall = false
+		return m.startInstallationPage()
+	case key.Matches(msg, m.keyMap.Back), key.Matches(msg, m.keyMap.Left), msg.String() == "n":
+		// Go back to changing the selection
+		m.confirmingInstall = false
+	case key.Matches(msg, m.keyMap.CopyCommand):
+		return m, m.copyCommandToClipboard(m.getSelectedPackages())
+	case key.Matches(msg, m.keyMap.MoveUp):
+		m.moveCategory(-1)
+	case key.Matches(msg, m.keyMap.MoveDown):
+		m.moveCategory(1)
+	case key.Matches(msg, m.keyMap.Up):
+		m.confirmCursor = max(m.confirmCursor-1, 0)
+	case key.Matches(msg, m.keyMap.Down):
+		m.confirmCursor = min(m.confirmCursor+1, max(len(m.orderedSelection())-1, 0))
+	}
+	return m, nil
+}
+
+// renderInstallConfirmation renders the package count confirmation
+func (m Model) renderInstallConfirmation() string {
+	boxStyle, _ := m.pageBox(accentColor, 20, 60)
+	question := fmt.Sprintf("Install %d packages?", m.confirmPackageCount)
+	if m.confirmPackageCount == 1 {
+		question = "Install 1 package?"
+	}
+
+	lines := []string{SelectionStyle.Render(question)}
+
+	// List the selected categories in the order they are installed
+	if selected := m.orderedSelection(); len(selected) > 1 {
+		var order []string
+		for i, name := range selected {
+			text := fmt.Sprintf("%d. %s: %s", i+1, name, strings.Join(m.selectedOptions[name], ", "))
+			order = append(order, m.renderOption(text, i == m.confirmCursor))
+		}
+		lines = append(lines,
+			"",
+			DimStyle.Render(m.label("Installation order, after the base packages:", "Installation order:")),
+			lipgloss.JoinVertical(lipgloss.Left, order...),
+			"",
+			DimStyle.Render(m.label("Up/Down to select, Shift+Up/Down to install earlier or later", "Shift+Up/Down reorder")),
+		)
+	}
+
+	copyKey := m.keyMap.CopyCommand.Help().Key
+	lines = append(lines,
+		DimStyle.Render(m.label("Enter to start the installation, Esc to change the selection", "Enter start, Esc back")),
+		DimStyle.Render(m.label(copyKey+" to copy the packages as an install command", copyKey+" copy command")),
+	)
+
+	return boxStyle.
+		Align(lipgloss.Center).
+		Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/diagnostics"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diagnosticsReport gathers the model state included in a diagnostics report
+func (m Model) diagnosticsReport() diagnostics.Report {
+	report := diagnostics.Report{
+		SelectedOptions:   m.selectedOptions,
+		InstalledPackages: m.installedPackages,
+		FailedPackages:    m.failedPackages,
+		LastError:         m.errorMessage,
+		LogPath:           m.options.LogPath,
+		Output:            m.outputText(),
+	}
+
+	if m.aurHelper != nil {
+		report.AURHelper = m.aurHelper.String()
+		if helper, ok := m.aurHelper.(*aur.Helper); ok {
+			report.AURHelperCommand = helper.Command
+		}
+	}
+
+	for pkg, skipped := range m.skippedPackages {
+		if skipped {
+			report.SkippedPackages = append(report.SkippedPackages, pkg)
+		}
+	}
+	sort.Strings(report.SkippedPackages)
+
+	return report
+}
+
+// writeDiagnosticsCmd returns a command that writes a diagnostics report for bug reports
+func (m Model) writeDiagnosticsCmd() tea.Cmd {
+	report := m.diagnosticsReport()
+	return func() tea.Msg {
+		path, err := diagnostics.Write(diagnostics.Collect(report))
+		if err != nil {
+			return NotificationMsg{
+				Type:    ui.ErrorNotification,
+				Title:   "Diagnostics Not Written",
+				Message: err.Error(),
+			}
+		}
+
+		return NotificationMsg{
+			Type:    ui.SuccessNotification,
+			Title:   "Diagnostics Written",
+			Message: fmt.Sprintf("Attach %s to your bug report", path),
+		}
+	}
+}
+
+// connectivityMsg carries the results of testing the connection to the AUR and the mirror
+type connectivityMsg struct {
+	Results []diagnostics.Result
+}
+
+// testConnectivity starts testing the connection to the AUR and the pacman mirror
+func (m *Model) testConnectivity() tea.Cmd {
+	if m.testingConnectivity {
+		return nil
+	}
+	m.testingConnectivity = true
+	m.connectivityResults = nil
+	return func() tea.Msg {
+		return connectivityMsg{Results: diagnostics.TestConnectivity()}
+	}
+}
+
+// handleConnectivity shows the connection test results on the failed page
+// and records them in the output
+func (m Model) handleConnectivity(msg connectivityMsg) (tea.Model, tea.Cmd) {
+	m.testingConnectivity = false
+	m.connectivityResults = msg.Results
+	for _, result := range msg.Results {
+		if result.Err != nil {
+			m.AddErrorMessage(result.String(), "connectivity")
+		} else {
+			m.AddInfoMessage(result.String(), "connectivity")
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/utils"
+)
+
+// diskUsage is the free space of each filesystem the installation writes to,
+// keyed by a path on it
+type diskUsage map[string]uint64
+
+// diskPaths returns a path on each filesystem the installation writes to:
+// the root filesystem for packages and the home directory for the dotfiles
+func diskPaths() []string {
+	paths := []string{"/"}
+	if homeDir, err := os.UserHomeDir(); err == nil && !utils.SameFilesystem("/", homeDir) {
+		paths = append(paths, homeDir)
+	}
+	return paths
+}
+
+// measureDiskUsage returns the free space of the filesystems of diskPaths,
+// leaving out the ones it can't be read for
+func measureDiskUsage() diskUsage {
+	usage := diskUsage{}
+	for _, path := range diskPaths() {
+		if free, err := utils.FreeSpace(path); err == nil {
+			usage[path] = free
+		}
+	}
+	return usage
+}
+
+// diskUsed returns how many bytes were used from before to after, negative
+// when more was freed, comparing only the filesystems measured both times
+func diskUsed(before, after diskUsage) int64 {
+	var used int64
+	for path, free := range before {
+		if freeAfter, ok := after[path]; ok {
+			used += int64(free) - int64(freeAfter)
+		}
+	}
+	return used
+}
+
+// diskSummary describes the disk space the installation used and the
+// cleanup freed, or returns "" when it wasn't measured
+func (m Model) diskSummary() string {
+	if len(m.diskBefore) == 0 || len(m.diskAfter) == 0 {
+		return ""
+	}
+
+	used := diskUsed(m.diskBefore, m.diskAfter)
+	summary := fmt.Sprintf("The installation freed %s of disk space", utils.FormatBytes(uint64(-used)))
+	if used >= 0 {
+		summary = fmt.Sprintf("The installation used %s of disk space", utils.FormatBytes(uint64(used)))
+	}
+	if m.cleanupFreed > 0 {
+		summary += fmt.Sprintf(", the cleanup freed %s", utils.FormatBytes(m.cleanupFreed))
+	}
+	return summary
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"errors"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrCancelled is reported in DoneMsg when the user quits before the
+// installation has finished
+var ErrCancelled = errors.New("installation cancelled")
+
+// DoneMsg is sent by an embedded model when the user leaves the installer.
+// Err is nil when the installation completed, the failure when it failed,
+// or ErrCancelled when the user quit early.
+type DoneMsg struct {
+	Err error
+}
+
+// NewEmbeddedModel creates a model to be driven by a parent bubbletea model.
+// Instead of quitting the program it sends a DoneMsg, so the parent can move
+// on once the installer is finished.
+func NewEmbeddedModel(opts config.Options) Model {
+	m := NewModelWithOptions(opts)
+	m.embedded = true
+	return m
+}
+
+// quit leaves the installer, quitting the program unless it is embedded
+func (m Model) quit() tea.Cmd {
+	if !m.embedded {
+		return tea.Quit
+	}
+
+	err := ErrCancelled
+	switch {
+	case m.page == CompletePage:
+		// Set on completion, so leaving from the log viewer still succeeds
+		err = nil
+	case m.errorMessage != "":
+		err = errors.New(m.errorMessage)
+	}
+	return func() tea.Msg {
+		return DoneMsg{Err: err}
+	}
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// extraPackagesCategory is the category extra packages are reported under
+const extraPackagesCategory = "Extra Packages"
+
+// handleExtraInput handles typing the comma-separated list of extra packages
+func (m Model) handleExtraInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		// Keep the previous list
+		m.extraFocused = false
+		m.extraInput = ""
+		return m, nil
+
+	case tea.KeyBackspace:
+		// Delete last character
+		if len(m.extraInput) > 0 {
+			m.extraInput = m.extraInput[:len(m.extraInput)-1]
+		}
+		return m, nil
+
+	case tea.KeyEnter:
+		packages := config.ParseList(m.extraInput)
+		if err := config.ValidatePackageNames(packages); err != nil {
+			return m, m.AddErrorNotification("Invalid Package", err.Error())
+		}
+
+		m.extraFocused = false
+		m.extraInput = ""
+		m.extraPackages = packages
+		if len(packages) == 0 {
+			return m, m.AddInfoNotification("Extra Packages", "No extra packages will be installed")
+		}
+		return m, checkExtraPackagesCmd(packages)
+
+	case tea.KeySpace:
+		m.extraInput += " "
+		return m, nil
+
+	default:
+		// Add character to the list
+		if msg.Type == tea.KeyRunes {
+			m.extraInput += string(msg.Runes)
+		}
+		return m, nil
+	}
+}
+
+// checkExtraPackagesCmd returns a command that warns about extra packages
+// missing from the official repositories, which need an AUR helper
+func checkExtraPackagesCmd(packages []string) tea.Cmd {
+	return func() tea.Msg {
+		var missing []string
+		for _, pkg := range packages {
+			if !aur.IsInOfficialRepos(pkg) {
+				missing = append(missing, pkg)
+			}
+		}
+
+		if len(missing) == 0 {
+			return NotificationMsg{
+				Type:    ui.SuccessNotification,
+				Title:   "Extra Packages",
+				Message: fmt.Sprintf("%d extra packages will be installed", len(packages)),
+			}
+		}
+		return NotificationMsg{
+			Type:    ui.WarningNotification,
+			Title:   "Not In Official Repositories",
+			Message: fmt.Sprintf("%s will be installed from the AUR if available", strings.Join(missing, ", ")),
+		}
+	}
+}
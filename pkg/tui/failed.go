@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// failedOutputLines is how many output lines the failed page shows
+const failedOutputLines = 8
+
+// Actions offered on the failed page
+const (
+	failedActionRetry   = "Retry"
+	failedActionNetwork = "Test connection"
+	failedActionShell   = "Open shell"
+	failedActionCopy    = "Copy log"
+	failedActionQuit    = "Quit"
+)
+
+// failedActions returns the actions available for the current failure.
+// Retry is only offered when the failed step can be run again.
+func (m Model) failedActions() []string {
+	actions := []string{failedActionNetwork, failedActionShell, failedActionCopy, failedActionQuit}
+	if m.retryStep != "" || m.failedStep != "" {
+		actions = append([]string{failedActionRetry}, actions...)
+	}
+	return actions
+}
+
+// failedStepName returns the name of the step that failed
+func (m Model) failedStepName() string {
+	if n := len(m.phases); n > 0 && m.phases[n-1].Status == PhaseFailed {
+		return m.phases[n-1].Name
+	}
+	return m.installPhase
+}
+
+// runFailedAction runs one of the failed page actions
+func (m Model) runFailedAction(action string) (tea.Model, tea.Cmd) {
+	switch action {
+	case failedActionRetry:
+		if m.retryStep == "" {
+			m.retryStep = m.failedStep
+		}
+		retryCmd := m.retryInstallation()
+		next, backCmd := m.router.Back(m)
+		return next, tea.Batch(backCmd, retryCmd)
+	case failedActionNetwork:
+		return m, m.testConnectivity()
+	case failedActionShell:
+		return m, m.openShell()
+	case failedActionCopy:
+		return m, m.copyOutputToClipboard()
+	case failedActionQuit:
+		return m, m.quit()
+	}
+	return m, nil
+}
+
+// updateFailedPage updates the failed page
+func (m Model) updateFailedPage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	actions := m.failedActions()
+	m.failedAction = min(m.failedAction, len(actions)-1)
+
+	// Run an action by its shortcut, or move between the actions and run one
+	switch {
+	case key.Matches(msg, m.keyMap.Retry) && actions[0] == failedActionRetry:
+		return m.runFailedAction(failedActionRetry)
+	case key.Matches(msg, m.keyMap.Shell):
+		return m.runFailedAction(failedActionShell)
+	case key.Matches(msg, m.keyMap.Copy):
+		return m.runFailedAction(failedActionCopy)
+	case key.Matches(msg, m.keyMap.Report):
+		return m, m.writeDiagnosticsCmd()
+	case key.Matches(msg, m.keyMap.View):
+		return m.openLogViewer()
+	case key.Matches(msg, m.keyMap.Log):
+		return m, m.openLogInPager()
+	case key.Matches(msg, m.keyMap.Left), key.Matches(msg, m.keyMap.Up):
+		m.failedAction = (m.failedAction - 1 + len(actions)) % len(actions)
+	case key.Matches(msg, m.keyMap.Right), key.Matches(msg, m.keyMap.Down), msg.Type == tea.KeyTab:
+		m.failedAction = (m.failedAction + 1) % len(actions)
+	case key.Matches(msg, m.keyMap.Enter), msg.Type == tea.KeySpace:
+		return m.runFailedAction(actions[m.failedAction])
+	}
+	return m, nil
+}
+
+// renderFailedPage renders the failed page
+func (m Model) renderFailedPage() string {
+	pageStyle := PageContainer.Copy().
+		Width(m.width)
+
+	titleStyle := TitleStyle.Copy().
+		Width(min(m.width, 80)).
+		Align(lipgloss.Center)
+
+	title := titleStyle.Render("Installation Failed")
+
+	// Describe the failure
+	boxStyle, boxWidth := m.pageBox(primaryColor, 20, 70)
+	boxStyle = boxStyle.Align(lipgloss.Center)
+
+	details := lipgloss.JoinVertical(
+		lipgloss.Left,
+		SubtitleStyle.Render("Failed step: ")+m.failedStepName(),
+		"",
+		ErrorStyle.Copy().Width(ui.ClampWidth(boxWidth-4)).Render(m.errorMessage),
+	)
+
+	// Render the last output lines
+	var lines []string
+	for _, message := range m.messageQueue.GetLast(failedOutputLines) {
+		lines = append(lines, message.Content)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, DimStyle.Render("No output"))
+	}
+	output := lipgloss.JoinVertical(
+		lipgloss.Left,
+		SubtitleStyle.Render("Last output"),
+		DimStyle.Copy().MaxWidth(ui.ClampWidth(boxWidth-4)).Render(strings.Join(lines, "\n")),
+	)
+
+	// Render the connection test results, telling network from package problems
+	var connectivity string
+	if m.testingConnectivity {
+		connectivity = boxStyle.Render(m.spinner.View() + " Testing the connection to the AUR and the mirror...")
+	} else if len(m.connectivityResults) > 0 {
+		results := []string{SubtitleStyle.Render("Connection test")}
+		for _, result := range m.connectivityResults {
+			style := SuccessStyle
+			if result.Err != nil {
+				style = ErrorStyle
+			}
+			results = append(results, style.Copy().Width(ui.ClampWidth(boxWidth-4)).Render(result.String()))
+		}
+		connectivity = boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, results...))
+	}
+
+	// Render the action buttons
+	actions := m.failedActions()
+	selected := min(m.failedAction, len(actions)-1)
+	buttons := make([]string, len(actions))
+	for i, action := range actions {
+		buttons[i] = m.renderButton(action, i == selected)
+	}
+	actionsStr := lipgloss.JoinHorizontal(lipgloss.Center, buttons...)
+
+	// Stack the actions since the buttons don't fit side by side
+	if m.compact() {
+		for i, action := range actions {
+			buttons[i] = m.renderOption(action, i == selected)
+		}
+		actionsStr = lipgloss.JoinVertical(lipgloss.Left, buttons...)
+	}
+
+	hint := DimStyle.Render(m.label("Use ←/→ to choose an action, b to write a bug report, v to view the log", "b bug report, v log"))
+
+	sections := []string{title, "", boxStyle.Render(details), boxStyle.Render(output)}
+	if connectivity != "" {
+		sections = append(sections, connectivity)
+	}
+	content := lipgloss.JoinVertical(lipgloss.Center, append(sections, "", actionsStr, hint)...)
+
+	return pageStyle.Render(content)
+}
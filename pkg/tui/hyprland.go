@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hyprlandVersionMsg carries the version of the installed Hyprland
+type hyprlandVersionMsg struct {
+	Version string
+	Err     error
+}
+
+// detectHyprlandVersionCmd returns a command that looks up the installed Hyprland version
+func detectHyprlandVersionCmd() tea.Cmd {
+	return func() tea.Msg {
+		version, err := system.HyprlandVersion()
+		return hyprlandVersionMsg{Version: version, Err: err}
+	}
+}
+
+// handleHyprlandVersion records the installed Hyprland version for the complete page
+func (m Model) handleHyprlandVersion(msg hyprlandVersionMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.AddWarningMessage(fmt.Sprintf("Could not detect the Hyprland version: %v", msg.Err), "hyprland")
+		return m, nil
+	}
+
+	m.hyprlandVersion = msg.Version
+	m.AddInfoMessage(fmt.Sprintf("Hyprland %s is installed", msg.Version), "hyprland")
+	return m, nil
+}
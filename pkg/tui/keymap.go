@@ -1,22 +1,42 @@
 package tui
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 )
 
 // KeyMap defines the keybindings for the application
 type KeyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Left   key.Binding
-	Right  key.Binding
-	Enter  key.Binding
-	Back   key.Binding
-	Tab    key.Binding
-	Help   key.Binding
-	Quit   key.Binding
-	Toggle key.Binding
-	Search key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	Left          key.Binding
+	Right         key.Binding
+	Enter         key.Binding
+	Back          key.Binding
+	Tab           key.Binding
+	Help          key.Binding
+	Quit          key.Binding
+	Toggle        key.Binding
+	Search        key.Binding
+	Copy          key.Binding
+	Reset         key.Binding
+	ResetAll      key.Binding
+	Advanced      key.Binding
+	Report        key.Binding
+	Retry         key.Binding
+	Log           key.Binding
+	Extra         key.Binding
+	Shell         key.Binding
+	Tag           key.Binding
+	View          key.Binding
+	Verbosity     key.Binding
+	Notifications key.Binding
+	MoveUp        key.Binding
+	MoveDown      key.Binding
+	CopyCommand   key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings
@@ -32,19 +52,19 @@ func DefaultKeyMap() KeyMap {
 		),
 		Left: key.NewBinding(
 			key.WithKeys("left", "h"),
-			key.WithHelp("←/h", "move left"),
+			key.WithHelp("←/h", "move left/back"),
 		),
 		Right: key.NewBinding(
 			key.WithKeys("right", "l"),
-			key.WithHelp("→/l", "move right"),
+			key.WithHelp("→/l", "move right/forward"),
 		),
 		Enter: key.NewBinding(
 			key.WithKeys("enter"),
-			key.WithHelp("enter", "select"),
+			key.WithHelp("enter", "select/confirm"),
 		),
 		Back: key.NewBinding(
 			key.WithKeys("esc", "backspace"),
-			key.WithHelp("esc", "back"),
+			key.WithHelp("esc", "go back"),
 		),
 		Tab: key.NewBinding(
 			key.WithKeys("tab"),
@@ -56,17 +76,179 @@ func DefaultKeyMap() KeyMap {
 		),
 		Quit: key.NewBinding(
 			key.WithKeys("ctrl+c", "q"),
-			key.WithHelp("ctrl+c", "quit"),
+			key.WithHelp("q/ctrl+c", "quit"),
 		),
 		Toggle: key.NewBinding(
 			key.WithKeys("space"),
-			key.WithHelp("space", "toggle"),
+			key.WithHelp("space", "toggle option"),
 		),
 		Search: key.NewBinding(
 			key.WithKeys("/"),
-			key.WithHelp("/", "search"),
+			key.WithHelp("/", "search options"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "copy command output"),
+		),
+		Reset: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "reset category to default"),
+		),
+		ResetAll: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "reset all to defaults"),
+		),
+		Advanced: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "toggle advanced mode"),
+		),
+		Report: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "write bug report diagnostics"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "retry a failed step or the failed packages"),
+		),
+		Log: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "view the install log in a pager"),
+		),
+		Extra: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "add extra packages"),
+		),
+		Shell: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "open a shell after a failure"),
 		),
+		Tag: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "filter options by tag"),
+		),
+		View: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "view and search the install log"),
+		),
+		Verbosity: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "change the command output verbosity"),
+		),
+		Notifications: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "hide or show notifications"),
+		),
+		MoveUp: key.NewBinding(
+			key.WithKeys("shift+up", "K"),
+			key.WithHelp("shift+↑/K", "install a category earlier"),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("shift+down", "J"),
+			key.WithHelp("shift+↓/J", "install a category later"),
+		),
+		CopyCommand: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "copy the packages as an install command"),
+		),
+	}
+}
+
+// actions returns the bindings of k by the action names used in the config file
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":            &k.Up,
+		"down":          &k.Down,
+		"left":          &k.Left,
+		"right":         &k.Right,
+		"enter":         &k.Enter,
+		"back":          &k.Back,
+		"tab":           &k.Tab,
+		"help":          &k.Help,
+		"quit":          &k.Quit,
+		"toggle":        &k.Toggle,
+		"search":        &k.Search,
+		"copy":          &k.Copy,
+		"reset":         &k.Reset,
+		"reset_all":     &k.ResetAll,
+		"advanced":      &k.Advanced,
+		"report":        &k.Report,
+		"retry":         &k.Retry,
+		"log":           &k.Log,
+		"extra":         &k.Extra,
+		"shell":         &k.Shell,
+		"tag":           &k.Tag,
+		"view":          &k.View,
+		"verbosity":     &k.Verbosity,
+		"notifications": &k.Notifications,
+		"move_up":       &k.MoveUp,
+		"move_down":     &k.MoveDown,
+		"copy_command":  &k.CopyCommand,
+	}
+}
+
+// WithOverrides returns k with the keys of the actions in overrides replaced.
+// It fails on unknown actions and on keys bound to two actions once the
+// overrides are applied.
+func (k KeyMap) WithOverrides(overrides map[string][]string) (KeyMap, error) {
+	actions := k.actions()
+
+	// Sort the actions so errors don't depend on map order
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		binding, ok := actions[name]
+		if !ok {
+			return k, fmt.Errorf("unknown key binding action %q", name)
+		}
+		keys := overrides[name]
+		if len(keys) == 0 {
+			return k, fmt.Errorf("no keys given for %q", name)
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+	}
+
+	if err := k.validate(); err != nil {
+		return k, err
+	}
+	return k, nil
+}
+
+// validate fails when a key is bound to more than one action
+func (k *KeyMap) validate() error {
+	actions := k.actions()
+
+	// Sort the actions so errors don't depend on map order
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	boundTo := make(map[string]string)
+	for _, name := range names {
+		for _, bound := range actions[name].Keys() {
+			if other, ok := boundTo[bound]; ok && other != name {
+				return fmt.Errorf("key %q is bound to both %q and %q", bound, other, name)
+			}
+			boundTo[bound] = name
+		}
+	}
+	return nil
+}
+
+// hint returns a footer hint for what bindings do on a page, from their help
+// keys like the help dropdown, so remapped keys show up as they are
+func hint(action string, bindings ...key.Binding) string {
+	keys := make([]string, 0, len(bindings))
+	for _, binding := range bindings {
+		keys = append(keys, binding.Help().Key)
 	}
+	return strings.Join(keys, " ") + " " + action
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view
@@ -74,11 +256,13 @@ func (k KeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{k.Help, k.Search, k.Quit}
 }
 
-// FullHelp returns keybindings for the expanded help view
+// FullHelp returns keybindings for the expanded help view, which the help
+// dropdown is rendered from
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
 		{k.Enter, k.Back, k.Tab, k.Toggle},
-		{k.Help, k.Search, k.Quit},
+		{k.Reset, k.ResetAll, k.Advanced, k.Extra, k.Tag, k.MoveUp, k.MoveDown},
+		{k.Help, k.Search, k.Copy, k.CopyCommand, k.Report, k.Retry, k.Shell, k.View, k.Log, k.Verbosity, k.Notifications, k.Quit},
 	}
 }
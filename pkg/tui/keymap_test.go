@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultKeyMapIsValid(t *testing.T) {
+	keyMap := DefaultKeyMap()
+	if err := keyMap.validate(); err != nil {
+		t.Fatalf("the default key bindings collide: %v", err)
+	}
+}
+
+func TestWithOverridesValidatesMergedKeyMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		collision string // Part of the error, empty for none
+	}{
+		{"redeclared default", map[string][]string{"log": {"p"}, "right": {"right", "l"}}, ""},
+		{"remapped key", map[string][]string{"retry": {"R"}}, ""},
+		{"default of another action", map[string][]string{"retry": {"p"}}, `"p"`},
+		{"two overrides", map[string][]string{"retry": {"R"}, "shell": {"R"}}, `"R"`},
+		{"freed key", map[string][]string{"log": {"L"}, "retry": {"p"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DefaultKeyMap().WithOverrides(tt.overrides)
+			switch {
+			case tt.collision == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.collision != "" && (err == nil || !strings.Contains(err.Error(), tt.collision)):
+				t.Errorf("error = %v, want a collision of %s", err, tt.collision)
+			}
+		})
+	}
+}
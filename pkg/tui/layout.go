@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// compactWidth is the terminal width below which pages use the compact
+// layout, e.g. in an SSH client on a phone
+const compactWidth = 60
+
+// compact reports whether the terminal is too narrow for bordered boxes
+func (m Model) compact() bool {
+	return m.width > 0 && m.width < compactWidth
+}
+
+// screenHeight returns the height pages fill: the terminal height on the
+// alternate screen, and none when rendering inline so only the content is drawn
+func (m Model) screenHeight() int {
+	if m.options.NoAltScreen {
+		return 0
+	}
+	return m.height
+}
+
+// pageBox returns the style of a box with a border in color, and its width:
+// the terminal width less margin, up to maxWidth. In the compact layout the
+// box drops its border and padding and spans the terminal instead.
+func (m Model) pageBox(color lipgloss.Color, margin, maxWidth int) (lipgloss.Style, int) {
+	if m.compact() {
+		return lipgloss.NewStyle().Width(m.width), m.width
+	}
+
+	width := min(m.width-margin, maxWidth)
+	return lipgloss.NewStyle().
+		Border(ui.Border()).
+		BorderForeground(color).
+		Padding(1, 2).
+		Width(width), width
+}
+
+// label returns short in the compact layout and full otherwise
+func (m Model) label(full, short string) string {
+	if m.compact() {
+		return short
+	}
+	return full
+}
+
+// selectInstructions are the instructions of the Up/Down selection prompts
+func (m Model) selectInstructions() string {
+	return InfoStyle.Render(m.label("Use Up/Down to select, Enter to confirm", "Up/Down, Enter"))
+}
+
+// wrapText wraps text to width, without padding the lines to it
+func wrapText(text string, width int) []string {
+	lines := strings.Split(lipgloss.NewStyle().Width(max(1, width)).Render(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return lines
+}
+
+// bulletList renders items as a bulleted list wrapped to width, with the
+// wrapped lines indented under the text of their item
+func bulletList(items []string, width int) string {
+	bullet := ui.Symbols.Bullet + " "
+	indent := lipgloss.Width(bullet)
+	textStyle := lipgloss.NewStyle().Foreground(textColor)
+
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		for i, line := range wrapText(item, width-indent) {
+			prefix := strings.Repeat(" ", indent)
+			if i == 0 {
+				prefix = bullet
+			}
+			lines = append(lines, textStyle.Render(prefix+line))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/messages"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// logFilters are the message types the log viewer cycles through after
+// showing all messages
+var logFilters = []messages.MessageType{
+	messages.ErrorMessage,
+	messages.WarningMessage,
+	messages.SuccessMessage,
+	messages.InfoMessage,
+}
+
+// logFilterNames name the entries of logFilters
+var logFilterNames = map[messages.MessageType]string{
+	messages.ErrorMessage:   "errors",
+	messages.WarningMessage: "warnings",
+	messages.SuccessMessage: "successes",
+	messages.InfoMessage:    "info",
+}
+
+// openLogViewer shows the log viewer scrolled to the latest messages
+func (m Model) openLogViewer() (tea.Model, tea.Cmd) {
+	m.logFilter = 0
+	m.logSearch = ""
+	m.logOffset = len(m.logMessages())
+	return m.router.Navigate(LogPage, m)
+}
+
+// logMessages returns the messages matching the type filter and search
+func (m Model) logMessages() []messages.Message {
+	if m.messageQueue == nil {
+		return nil
+	}
+
+	var list []messages.Message
+	if m.logFilter > 0 {
+		list = m.messageQueue.FilterByType(logFilters[m.logFilter-1])
+	} else {
+		list = m.messageQueue.Get()
+	}
+
+	if m.logSearch == "" {
+		return list
+	}
+	query := strings.ToLower(m.logSearch)
+	matches := []messages.Message{}
+	for _, msg := range list {
+		if strings.Contains(strings.ToLower(msg.Content), query) {
+			matches = append(matches, msg)
+		}
+	}
+	return matches
+}
+
+// logViewHeight returns how many messages the log viewer shows at once
+func (m Model) logViewHeight() int {
+	return max(5, m.height-12)
+}
+
+// scrollLog moves the log viewer by delta lines, staying within the messages
+func (m *Model) scrollLog(delta int) {
+	maxOffset := max(0, len(m.logMessages())-m.logViewHeight())
+	m.logOffset = max(0, min(m.logOffset+delta, maxOffset))
+}
+
+// updateLogPage updates the log viewer page
+func (m Model) updateLogPage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.logViewHeight()
+
+	switch {
+	case key.Matches(msg, m.keyMap.Up):
+		m.scrollLog(-1)
+	case key.Matches(msg, m.keyMap.Down):
+		m.scrollLog(1)
+	case msg.Type == tea.KeyPgUp:
+		m.scrollLog(-page)
+	case msg.Type == tea.KeyPgDown, msg.Type == tea.KeySpace:
+		m.scrollLog(page)
+	case msg.Type == tea.KeyHome:
+		m.logOffset = 0
+	case msg.Type == tea.KeyEnd:
+		m.scrollLog(len(m.logMessages()))
+	case key.Matches(msg, m.keyMap.Tab):
+		// Cycle the type filter and show the latest matching messages
+		m.logFilter = (m.logFilter + 1) % (len(logFilters) + 1)
+		m.logOffset = 0
+		m.scrollLog(len(m.logMessages()))
+	case key.Matches(msg, m.keyMap.Search):
+		m.logSearchFocused = true
+	case key.Matches(msg, m.keyMap.Copy):
+		return m, m.copyOutputToClipboard()
+	case key.Matches(msg, m.keyMap.Log):
+		return m, m.openLogInPager()
+	}
+	return m, nil
+}
+
+// handleLogSearchInput edits the log viewer search, which filters the
+// messages as it is typed
+func (m Model) handleLogSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		// Clear the search
+		m.logSearchFocused = false
+		m.logSearch = ""
+	case tea.KeyEnter:
+		// Keep the search and go back to scrolling
+		m.logSearchFocused = false
+	case tea.KeyBackspace:
+		if len(m.logSearch) > 0 {
+			m.logSearch = m.logSearch[:len(m.logSearch)-1]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.logSearch += string(msg.Runes)
+	default:
+		return m, nil
+	}
+
+	m.logOffset = 0
+	m.scrollLog(len(m.logMessages()))
+	return m, nil
+}
+
+// renderLogPage renders the log viewer page
+func (m Model) renderLogPage() string {
+	pageStyle := PageContainer.Copy().
+		Width(m.width)
+
+	titleStyle := TitleStyle.Copy().
+		Width(min(m.width, 80)).
+		Align(lipgloss.Center)
+
+	title := titleStyle.Render("Install Log")
+
+	boxStyle, boxWidth := m.pageBox(primaryColor, 10, 100)
+	boxStyle = boxStyle.Align(lipgloss.Left)
+	lineStyle := lipgloss.NewStyle().MaxWidth(ui.ClampWidth(boxWidth - 6))
+
+	// Describe what is shown
+	filter := "all messages"
+	if m.logFilter > 0 {
+		filter = logFilterNames[logFilters[m.logFilter-1]]
+	}
+	list := m.logMessages()
+	status := fmt.Sprintf("Showing %s (%d)", filter, len(list))
+
+	search := DimStyle.Render("/ to search")
+	if m.logSearchFocused || m.logSearch != "" {
+		cursor := ""
+		if m.logSearchFocused {
+			cursor = "_"
+		}
+		search = SelectionStyle.Render("Search: " + m.logSearch + cursor)
+	}
+
+	// Render the visible window of messages
+	height := m.logViewHeight()
+	offset := max(0, min(m.logOffset, len(list)-height))
+	end := min(len(list), offset+height)
+
+	styles := map[messages.MessageType]lipgloss.Style{
+		messages.ErrorMessage:   ErrorStyle,
+		messages.WarningMessage: WarningStyle,
+		messages.SuccessMessage: SuccessStyle,
+		messages.DebugMessage:   DimStyle,
+	}
+
+	lines := make([]string, 0, height)
+	for _, msg := range list[offset:end] {
+		// Highlighting replaces the type colors while searching
+		line := msg.Render(styles)
+		if m.logSearch != "" {
+			line = ui.HighlightMatch(msg.Content, m.logSearch)
+		}
+		lines = append(lines, lineStyle.Render(line))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, DimStyle.Render("No matching messages"))
+	}
+
+	position := DimStyle.Render(fmt.Sprintf("%d-%d of %d", min(offset+1, end), end, len(list)))
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Center,
+		title,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, SubtitleStyle.Render(status), search),
+		boxStyle.Render(strings.Join(lines, "\n")),
+		position,
+	)
+
+	return pageStyle.Render(content)
+}
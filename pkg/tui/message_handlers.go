@@ -1,9 +1,13 @@
 package tui
 
 import (
+	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/messages"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // AddInfoMessage adds an info message to the message queue
@@ -61,29 +65,62 @@ func (m *Model) AddDebugMessage(content string, source string) {
 	m.systemMessages = append(m.systemMessages, content)
 }
 
-// AddMessage adds a message to the message queue with automatic type detection
+// AddMessage adds a message to the message queue with automatic type
+// detection. Messages the verbosity hides are only written to the log.
 func (m *Model) AddMessage(content string, source string) {
 	// Determine message type based on content
 	content = strings.TrimSpace(content)
 	lowerContent := strings.ToLower(content)
 
+	msgType := messages.InfoMessage
 	switch {
-	case strings.Contains(lowerContent, "error") || 
-	     strings.Contains(lowerContent, "failed") || 
-	     strings.Contains(lowerContent, "conflict"):
-		m.AddErrorMessage(content, source)
-	case strings.Contains(lowerContent, "warning") || 
-	     strings.Contains(lowerContent, "caution"):
-		m.AddWarningMessage(content, source)
-	case strings.Contains(lowerContent, "success") || 
-	     strings.Contains(lowerContent, "complete") || 
-	     strings.Contains(lowerContent, "installed"):
-		m.AddSuccessMessage(content, source)
+	case strings.Contains(lowerContent, "error") ||
+		strings.Contains(lowerContent, "failed") ||
+		strings.Contains(lowerContent, "conflict"):
+		msgType = messages.ErrorMessage
+	case strings.Contains(lowerContent, "warning") ||
+		strings.Contains(lowerContent, "caution"):
+		msgType = messages.WarningMessage
+	case strings.Contains(lowerContent, "success") ||
+		strings.Contains(lowerContent, "complete") ||
+		strings.Contains(lowerContent, "installed"):
+		msgType = messages.SuccessMessage
 	case strings.Contains(lowerContent, "debug"):
-		m.AddDebugMessage(content, source)
-	default:
-		m.AddInfoMessage(content, source)
+		msgType = messages.DebugMessage
 	}
+
+	if !m.showsMessage(msgType) {
+		if m.messageQueue != nil {
+			m.messageQueue.Log(messages.NewMessage(msgType, content, source))
+		}
+		return
+	}
+
+	// Add to the message queue
+	if m.messageQueue != nil {
+		m.messageQueue.Add(messages.NewMessage(msgType, content, source))
+	}
+
+	// Also add to the legacy system messages for backward compatibility
+	m.systemMessages = append(m.systemMessages, content)
+}
+
+// showsMessage reports whether the verbosity shows messages of msgType
+func (m *Model) showsMessage(msgType messages.MessageType) bool {
+	switch m.verbosity {
+	case config.VerbosityQuiet:
+		return msgType == messages.WarningMessage || msgType == messages.ErrorMessage || msgType == messages.SuccessMessage
+	case config.VerbosityVerbose:
+		return true
+	}
+	return msgType != messages.DebugMessage
+}
+
+// cycleVerbosity switches to the next verbosity, wrapping around to quiet
+func (m *Model) cycleVerbosity() tea.Cmd {
+	next := (slices.Index(config.Verbosities, m.verbosity) + 1) % len(config.Verbosities)
+	m.verbosity = config.Verbosities[next]
+	return m.AddInfoNotification("Verbosity", fmt.Sprintf("Showing %s output", m.verbosity))
 }
 
 // ClearMessages clears all messages from the message queue
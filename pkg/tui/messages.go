@@ -4,16 +4,43 @@ import "time"
 
 // InstallProgressMsg represents a message for installation progress updates
 type InstallProgressMsg struct {
-	Progress               int
-	Total                  int
-	CurrentStep            string
-	Error                  error
-	Phase                  string
-	IsComplete             bool
-	HasConflict            bool
-	Conflict               string
-	IsDotfilesConfirmation bool
-	IsBackupConfirmation   bool
+	Progress                int
+	Total                   int
+	CurrentStep             string
+	Error                   error
+	Phase                   string
+	IsComplete              bool
+	HasConflict             bool
+	Conflict                string
+	IsDotfilesConfirmation  bool
+	IsBackupConfirmation    bool
+	IsOverwriteConfirmation bool
+	IsServicesConfirmation  bool
+	IsWallpaperSelection    bool
+	DownloadedBytes         uint64
+	DownloadTotalBytes      uint64
+	CategoryProgress        string
+
+	// Output produced by the command, added to the message log by Update
+	Output []string
+	Source string
+
+	// RetryStep names the step to run again if it failed
+	RetryStep string
+
+	// State changes reported back by the command
+	ConflictPackage    string
+	InstalledPackage   string
+	FailedPackage      string
+	SkippedPackage     string
+	PackagesDownloaded bool
+	AURHelperInstalled bool
+	BackupDir          string
+	DotfilesInstalled  bool
+	WallpaperScript    string
+	Wallpapers         []string
+	Instructions       []string // Post-install instructions shipped with the dotfiles
+	CleanupFreed       uint64   // Bytes freed by removing the cloned repository
 }
 
 // PageTransitionMsg represents a message for page transitions with animation
@@ -68,6 +95,44 @@ func NewBackupConfirmationMsg() InstallProgressMsg {
 	}
 }
 
+// NewOverwriteConfirmationMsg creates a new InstallProgressMsg for confirming deletion of an existing checkout
+func NewOverwriteConfirmationMsg() InstallProgressMsg {
+	return InstallProgressMsg{
+		IsOverwriteConfirmation: true,
+	}
+}
+
+// NewWallpaperSelectionMsg creates a new InstallProgressMsg for choosing a wallpaper
+func NewWallpaperSelectionMsg() InstallProgressMsg {
+	return InstallProgressMsg{
+		IsWallpaperSelection: true,
+	}
+}
+
+// NewServicesConfirmationMsg creates a new InstallProgressMsg for confirming enabling services
+func NewServicesConfirmationMsg() InstallProgressMsg {
+	return InstallProgressMsg{
+		IsServicesConfirmation: true,
+	}
+}
+
+// promptPhase returns the install phase of the prompt msg asks, if any
+func (msg InstallProgressMsg) promptPhase() string {
+	switch {
+	case msg.IsDotfilesConfirmation:
+		return "dotfiles_confirmation"
+	case msg.IsBackupConfirmation:
+		return "backup_confirmation"
+	case msg.IsOverwriteConfirmation:
+		return "overwrite_confirmation"
+	case msg.IsWallpaperSelection:
+		return "wallpaper_selection"
+	case msg.IsServicesConfirmation:
+		return "services_confirmation"
+	}
+	return ""
+}
+
 // NewPageTransitionMsg creates a new PageTransitionMsg
 func NewPageTransitionMsg(fromPage, toPage Page, animType string, duration time.Duration) PageTransitionMsg {
 	return PageTransitionMsg{
@@ -1,21 +1,54 @@
 package messages
 
 import (
+	"fmt"
+	"io"
 	"sync"
 )
 
+// Defaults for the queue size and truncating a full queue
+const (
+	DefaultMaxSize           = 100
+	DefaultTruncationMessage = "... (messages truncated) ..."
+	DefaultHeadRatio         = 0.25
+)
+
 // Queue represents a message queue
 type Queue struct {
-	messages []Message
-	maxSize  int
-	mu       sync.Mutex
+	messages          []Message
+	maxSize           int
+	headRatio         float64 // Share of maxSize kept from the start when truncating
+	truncationMessage string
+	mu                sync.Mutex
+	logWriter         io.Writer
 }
 
-// NewQueue creates a new message queue
+// NewQueue creates a new message queue. A maxSize without room for the
+// truncation message and a message falls back to DefaultMaxSize.
 func NewQueue(maxSize int) *Queue {
+	if maxSize < 2 {
+		maxSize = DefaultMaxSize
+	}
+
 	return &Queue{
-		messages: make([]Message, 0),
-		maxSize:  maxSize,
+		messages:          make([]Message, 0),
+		maxSize:           maxSize,
+		headRatio:         DefaultHeadRatio,
+		truncationMessage: DefaultTruncationMessage,
+	}
+}
+
+// SetTruncation sets the message that replaces dropped messages and the share
+// of the queue, between 0 and 1, kept from the start when it is truncated
+func (q *Queue) SetTruncation(message string, headRatio float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if message != "" {
+		q.truncationMessage = message
+	}
+	if headRatio >= 0 && headRatio < 1 {
+		q.headRatio = headRatio
 	}
 }
 
@@ -27,25 +60,51 @@ func (q *Queue) Add(msg Message) {
 	// Add the message
 	q.messages = append(q.messages, msg)
 
+	// Write the message to the log before it can be truncated
+	q.writeLog(msg)
+
 	// Trim the queue if it exceeds the maximum size
 	if len(q.messages) > q.maxSize {
-		// Keep the first quarter and last three quarters
-		firstQuarter := q.maxSize / 4
-		lastThreeQuarters := q.maxSize - firstQuarter - 1 // -1 for the truncation message
+		// Keep the head and the most recent messages
+		head := int(float64(q.maxSize) * q.headRatio)
+		tail := q.maxSize - head - 1 // -1 for the truncation message
 
 		truncatedMessages := make([]Message, 0, q.maxSize)
-		truncatedMessages = append(truncatedMessages, q.messages[:firstQuarter]...)
-		
+		truncatedMessages = append(truncatedMessages, q.messages[:head]...)
+
 		// Add a truncation message
-		truncatedMessages = append(truncatedMessages, NewInfoMessage("... (messages truncated) ...", "system"))
-		
-		// Add the last three quarters
-		truncatedMessages = append(truncatedMessages, q.messages[len(q.messages)-lastThreeQuarters:]...)
-		
+		truncatedMessages = append(truncatedMessages, NewInfoMessage(q.truncationMessage, "system"))
+
+		// Add the most recent messages
+		truncatedMessages = append(truncatedMessages, q.messages[len(q.messages)-tail:]...)
+
 		q.messages = truncatedMessages
 	}
 }
 
+// Log writes a message to the log without adding it to the queue, for
+// messages that are hidden but shouldn't be lost
+func (q *Queue) Log(msg Message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.writeLog(msg)
+}
+
+// writeLog writes a message to the log writer, if any
+func (q *Queue) writeLog(msg Message) {
+	if q.logWriter != nil {
+		fmt.Fprintf(q.logWriter, "%s [%s] %s\n", msg.Timestamp.Format("2006-01-02 15:04:05"), msg.Source, msg.Content)
+	}
+}
+
+// SetLogWriter sets a writer that receives every message added to the queue
+func (q *Queue) SetLogWriter(w io.Writer) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.logWriter = w
+}
+
 // Get returns all messages
 func (q *Queue) Get() []Message {
 	q.mu.Lock()
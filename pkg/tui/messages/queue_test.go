@@ -0,0 +1,86 @@
+package messages
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestQueueTruncationKeepsHeadAndTail(t *testing.T) {
+	const marker = "-- truncated --"
+
+	for _, maxSize := range []int{2, 4, 10, 100} {
+		for _, headRatio := range []float64{0, 0.25, 0.5, 0.9} {
+			t.Run(fmt.Sprintf("max %d head %.2f", maxSize, headRatio), func(t *testing.T) {
+				q := NewQueue(maxSize)
+				q.SetTruncation(marker, headRatio)
+
+				added := 10*maxSize + 7
+				for i := 0; i < added; i++ {
+					q.Add(NewInfoMessage(fmt.Sprintf("message %d", i), "test"))
+				}
+
+				got := q.Get()
+				if len(got) != maxSize {
+					t.Fatalf("queue holds %d messages, want %d", len(got), maxSize)
+				}
+
+				// The first messages, the marker, then the most recent messages
+				head := int(float64(maxSize) * headRatio)
+				tail := maxSize - head - 1
+				var want []string
+				for i := 0; i < head; i++ {
+					want = append(want, fmt.Sprintf("message %d", i))
+				}
+				want = append(want, marker)
+				for i := added - tail; i < added; i++ {
+					want = append(want, fmt.Sprintf("message %d", i))
+				}
+
+				markers := 0
+				for i, msg := range got {
+					if msg.Content != want[i] {
+						t.Errorf("message %d = %q, want %q", i, msg.Content, want[i])
+					}
+					if msg.Content == marker {
+						markers++
+					}
+				}
+				if markers != 1 {
+					t.Errorf("the truncation marker appears %d times, want once", markers)
+				}
+			})
+		}
+	}
+}
+
+func TestQueueWithinMaxSizeIsNotTruncated(t *testing.T) {
+	q := NewQueue(5)
+	for i := 0; i < 5; i++ {
+		q.Add(NewInfoMessage(fmt.Sprintf("message %d", i), "test"))
+	}
+
+	got := q.Get()
+	if len(got) != 5 {
+		t.Fatalf("queue holds %d messages, want 5", len(got))
+	}
+	for i, msg := range got {
+		if want := fmt.Sprintf("message %d", i); msg.Content != want {
+			t.Errorf("message %d = %q, want %q", i, msg.Content, want)
+		}
+	}
+}
+
+func TestQueueTooSmallFallsBackToDefault(t *testing.T) {
+	for _, maxSize := range []int{-1, 0, 1} {
+		t.Run(fmt.Sprintf("max %d", maxSize), func(t *testing.T) {
+			q := NewQueue(maxSize)
+			for i := 0; i < DefaultMaxSize+10; i++ {
+				q.Add(NewInfoMessage(fmt.Sprintf("message %d", i), "test"))
+			}
+
+			if got := len(q.Get()); got != DefaultMaxSize {
+				t.Fatalf("queue holds %d messages, want %d", got, DefaultMaxSize)
+			}
+		})
+	}
+}
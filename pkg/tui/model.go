@@ -1,8 +1,12 @@
 package tui
 
 import (
-	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/diagnostics"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/messages"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
 	"github.com/charmbracelet/bubbles/help"
@@ -20,6 +24,8 @@ const (
 	PackageCategoriesPage
 	InstallationPage
 	CompletePage
+	FailedPage
+	LogPage
 )
 
 // Import KeyMap from keymap.go
@@ -36,6 +42,9 @@ type Model struct {
 	router          *Router
 	messageQueue    *messages.Queue
 	messageRenderer *messages.Renderer
+	verbosity       config.Verbosity // Command output shown, see showsMessage
+	options         config.Options
+	embedded        bool // Driven by a parent model, see NewEmbeddedModel
 
 	// Animation
 	animation   ui.AnimationState
@@ -46,8 +55,14 @@ type Model struct {
 	// AUR helper
 	aurHelperOptions   []string
 	aurHelperIndex     int
-	aurHelper          *aur.Helper
-	aurHelperInstalled bool // Track if the AUR helper is installed
+	aurHelper          PackageManager
+	aurHelperInstalled bool               // Track if the AUR helper is installed
+	packagesDownloaded bool               // Track if the packages were downloaded up front
+	resume             *installCheckpoint // Installation to resume with --resume, if one was interrupted
+	skippedAURHelper   string             // AUR helper not built since every package was in the official repositories
+
+	// Creates the package manager in place of the AUR helper, set by tests
+	newPackageManager func(name string) PackageManager
 
 	// Package selection
 	categories       []config.PackageCategory
@@ -55,11 +70,48 @@ type Model struct {
 	optionIndex      int
 	selectedOptions  map[string][]string
 	selectedCategory int
+	pendingOption    string // Experimental option waiting for a second toggle to be selected
+
+	// Package count confirmation, see confirmInstall
+	confirmingInstall   bool
+	confirmPackageCount int
+	confirmCursor       int      // Selected category in the installation order of the confirmation
+	categoryOrder       []string // Category names in the order their packages are installed, see installOrder
+
+	// Availability check, see startInstallationPage
+	checkingAvailability bool
+	checkedPackages      string   // Selection the last check reported missing packages for
+	missingPackages      []string // Packages the last check found neither in the repositories nor in the AUR
+
+	// Advanced mode
+	advancedMode           bool
+	deselectedBasePackages map[string]bool
 
 	// Search
 	searchQuery     string
 	searchFocused   bool
 	filteredOptions []string
+	tagFilter       string // Only options with this tag are shown when set
+	tagIndex        int    // Selected option among those matching tagFilter
+
+	// Extra packages
+	extraPackages []string
+	extraInput    string
+	extraFocused  bool
+
+	// Excluded packages
+	excludedPackages []string // Never installed, from --exclude
+	excluded         []string // Selected packages left out because they're excluded
+
+	// Packages queued when the installation started, copied as an install
+	// command from the complete page
+	queuedPackages []string
+
+	// Log viewer
+	logOffset        int    // First message shown
+	logFilter        int    // 0 shows all messages, otherwise the index in logFilters plus one
+	logSearch        string // Only messages containing this are shown when set
+	logSearchFocused bool
 
 	// Installation state
 	installProgress   int
@@ -68,10 +120,38 @@ type Model struct {
 	installError      string
 	installComplete   bool
 	packagesToInstall []string
+	packageOrigins    map[string]packageOrigin
+	currentCategory   string
 	totalSteps        int
+	stepWeights       []int // Progress weight of each step, see installStepWeights
 	currentStep       string
 	installPhase      string
 	errorMessage      string
+	retryStep         string
+	failedStep        string    // RetryStep of the last failure, retryable after fixing it in a shell
+	failedAction      int       // Selected action on the failed page
+	lastActivity      time.Time // Last install progress, command output or key press, see handleWatchdogTick
+	stallErr          error     // Set when the watchdog aborted the installation
+	startTime         time.Time
+	phases            []PhaseResult // Timeline of the installation phases, see beginPhase
+	downloadedBytes   uint64
+	downloadTotal     uint64
+	cloneStage        string // Stage of the running git clone, e.g. "Receiving objects"
+	clonePercent      int
+	cloning           bool
+	installedPackages []string
+	failedPackages    []string
+	dotfilesInstalled bool
+	backupDir         string
+
+	// Free disk space before and after the installation, and the space the cleanup freed
+	diskBefore   diskUsage
+	diskAfter    diskUsage
+	cleanupFreed uint64
+
+	// Connection test of the failed page
+	testingConnectivity bool
+	connectivityResults []diagnostics.Result
 
 	// Task progress
 	tasks            []ui.TaskProgress
@@ -83,6 +163,14 @@ type Model struct {
 	awaitingPassword bool
 	passwordVisible  bool
 
+	// Preflight checks
+	distroName        string
+	unsupportedDistro bool
+	distroConfirmed   bool
+	preflightWarnings []string
+	homeError         error  // Set by the preflight checks when the home directory isn't writable
+	liveSession       string // Graphical session the installer runs in, if any
+
 	// Notifications
 	notifications     []ui.Notification
 	showNotifications bool
@@ -97,27 +185,81 @@ type Model struct {
 	replaceAllPackages bool            // Track if we should replace all packages
 
 	// Installation phases
-	installationPhase    string   // Current installation phase: "packages" or "post-installation"
-	phaseMessageShown    bool     // Track if we've shown the phase transition message
-	repoCloned           bool     // Track if we've cloned the repository
-	configDirIndex       int      // Track which config directory we're currently processing
-	dotfilesConfirmation bool     // Track if the user wants to install dotfiles
-	backupConfirmation   bool     // Track if the user wants to backup existing config
-	systemMessages       []string // Store system messages for display (legacy, will be replaced by messageQueue)
+	installationPhase     string   // Current installation phase: "packages" or "post-installation"
+	retryingFailed        bool     // Only the failed packages are installed again, see retryFailedPackages
+	completeRetry         bool     // Retry failed is selected on the complete page instead of Exit
+	phaseMessageShown     bool     // Track if we've shown the phase transition message
+	repoCloned            bool     // Track if we've cloned the repository
+	configDirIndex        int      // Track which config directory we're currently processing
+	dotfilesConfirmation  bool     // Track if the user wants to install dotfiles
+	backupConfirmation    bool     // Track if the user wants to backup existing config
+	overwriteConfirmation bool     // Track if the user allows deleting an existing ~/HyprLuna
+	servicesConfirmation  bool     // Track if the user wants the selected services enabled
+	servicesHandled       bool     // Track if we've already asked about enabling services
+	wallpaperScript       string   // Color generation script, set when there are wallpapers to choose from
+	instructions          []string // Post-install instructions from the dotfiles, replacing the built-in ones
+	hyprlandVersion       string   // Version of the installed Hyprland, shown on the complete page
+	wallpapers            []string // Wallpapers offered on the wallpaper selection
+	wallpaperIndex        int      // Selected wallpaper: 0=Random, otherwise wallpapers[wallpaperIndex-1]
+	wallpaperHandled      bool     // Track if we've already generated the wallpaper colors
+	systemMessages        []string // Store system messages for display (legacy, will be replaced by messageQueue)
+
+	// Force quitting
+	lastInterrupt time.Time // Last Ctrl+C press, see ForceQuitWindow
 }
 
-// NewModel creates a new model
+// NewModel creates a new model with the default options
 func NewModel() Model {
+	return NewModelWithOptions(config.DefaultOptions())
+}
+
+// spinnerStyles maps the names of config.SpinnerStyles to their spinners
+var spinnerStyles = map[string]spinner.Spinner{
+	"line":      spinner.Line,
+	"dot":       spinner.Dot,
+	"minidot":   spinner.MiniDot,
+	"jump":      spinner.Jump,
+	"pulse":     spinner.Pulse,
+	"points":    spinner.Points,
+	"globe":     spinner.Globe,
+	"moon":      spinner.Moon,
+	"monkey":    spinner.Monkey,
+	"meter":     spinner.Meter,
+	"hamburger": spinner.Hamburger,
+	"ellipsis":  spinner.Ellipsis,
+}
+
+// NewModelWithOptions creates a new model with the given options
+func NewModelWithOptions(opts config.Options) Model {
+	// Switch to ASCII markers and a black-and-white palette before any style is used
+	if opts.ASCII {
+		ui.UseASCII()
+		loadStyles()
+	}
+
 	// Initialize spinner
 	s := spinner.New()
 	s.Spinner = spinner.Dot
+	if opts.ASCII {
+		s.Spinner = spinner.Line
+	}
+	if style, ok := spinnerStyles[opts.Spinner]; ok {
+		s.Spinner = style
+	}
 	s.Style = lipgloss.NewStyle().Foreground(ui.PrimaryColor).Bold(true)
 
+	// Apply the key bindings from the config file, main validates them first
+	keyMap, err := DefaultKeyMap().WithOverrides(opts.KeyBindings)
+	if err != nil {
+		keyMap = DefaultKeyMap()
+	}
+
 	// Initialize router
 	router := NewRouter()
 
 	// Initialize message queue and renderer
-	messageQueue := messages.NewQueue(100)          // Store up to 100 messages
+	messageQueue := messages.NewQueue(opts.MessageLimit)
+	messageQueue.SetTruncation(opts.TruncationMessage, opts.TruncationHead)
 	messageRenderer := messages.NewRenderer(80, 15) // Default width and height
 
 	// Set message styles
@@ -129,112 +271,204 @@ func NewModel() Model {
 
 	// Create model
 	m := Model{
-		keyMap:               DefaultKeyMap(),
-		help:                 help.New(),
-		spinner:              s,
-		page:                 WelcomePage,
-		router:               router,
-		messageQueue:         messageQueue,
-		messageRenderer:      messageRenderer,
-		animation:            ui.AnimationState{},
-		animating:            false,
-		prevContent:          "",
-		nextContent:          "",
-		aurHelperOptions:     config.AURHelpers,
-		aurHelperIndex:       0,
-		aurHelperInstalled:   false,
-		categories:           config.PackageCategories,
-		categoryIndex:        0,
-		optionIndex:          -1,
-		selectedOptions:      make(map[string][]string),
-		selectedCategory:     0,
-		searchQuery:          "",
-		searchFocused:        false,
-		filteredOptions:      []string{},
-		installProgress:      0,
-		installTotal:         0,
-		installCurrent:       "",
-		installError:         "",
-		installComplete:      false,
-		tasks:                make([]ui.TaskProgress, 0),
-		indeterminatePos:     0,
-		showHelp:             false,
-		passwordInput:        "",
-		awaitingPassword:     false,
-		passwordVisible:      true,
-		notifications:        make([]ui.Notification, 0),
-		showNotifications:    true,
-		hasConflict:          false,
-		conflictMessage:      "",
-		conflictChoice:       true,
-		conflictOption:       0,
-		conflictPackage:      "",
-		skippedPackages:      make(map[string]bool),
-		replaceAllPackages:   false,
-		installationPhase:    "",
-		phaseMessageShown:    false,
-		repoCloned:           false,
-		configDirIndex:       0,
-		dotfilesConfirmation: false,
-		backupConfirmation:   false,
-		systemMessages:       make([]string, 0),
-		packagesToInstall:    make([]string, 0),
+		keyMap:                 keyMap,
+		help:                   help.New(),
+		spinner:                s,
+		page:                   WelcomePage,
+		router:                 router,
+		messageQueue:           messageQueue,
+		messageRenderer:        messageRenderer,
+		verbosity:              opts.Verbosity,
+		options:                opts,
+		animation:              ui.AnimationState{},
+		animating:              false,
+		prevContent:            "",
+		nextContent:            "",
+		aurHelperOptions:       config.AURHelpers,
+		aurHelperIndex:         0,
+		aurHelperInstalled:     false,
+		categories:             config.MergeCategories(config.PackageCategories, opts.CustomCategories),
+		categoryIndex:          0,
+		optionIndex:            -1,
+		selectedOptions:        make(map[string][]string),
+		selectedCategory:       0,
+		advancedMode:           false,
+		deselectedBasePackages: make(map[string]bool),
+		searchQuery:            "",
+		searchFocused:          false,
+		filteredOptions:        []string{},
+		extraPackages:          append([]string(nil), opts.ExtraPackages...),
+		excludedPackages:       append([]string(nil), opts.ExcludedPackages...),
+		installProgress:        0,
+		installTotal:           0,
+		installCurrent:         "",
+		installError:           "",
+		installComplete:        false,
+		tasks:                  make([]ui.TaskProgress, 0),
+		indeterminatePos:       0,
+		showHelp:               false,
+		passwordInput:          "",
+		awaitingPassword:       false,
+		passwordVisible:        true,
+		notifications:          make([]ui.Notification, 0),
+		showNotifications:      true,
+		hasConflict:            false,
+		conflictMessage:        "",
+		conflictChoice:         true,
+		conflictOption:         0,
+		conflictPackage:        "",
+		skippedPackages:        make(map[string]bool),
+		replaceAllPackages:     opts.AssumeYes,
+		installationPhase:      "",
+		phaseMessageShown:      false,
+		repoCloned:             false,
+		configDirIndex:         0,
+		dotfilesConfirmation:   opts.AssumeYes,
+		backupConfirmation:     opts.AssumeYes,
+		servicesConfirmation:   opts.AssumeYes,
+		systemMessages:         make([]string, 0),
+		packagesToInstall:      make([]string, 0),
+		packageOrigins:         make(map[string]packageOrigin),
+		installedPackages:      make([]string, 0),
+		failedPackages:         make([]string, 0),
 	}
 
+	// Preselect the configured AUR helper
+	for i, helper := range m.aurHelperOptions {
+		if helper == opts.AURHelper {
+			m.aurHelperIndex = i
+		}
+	}
+
+	// Append every message to the log file if configured
+	if opts.LogPath != "" {
+		if logFile, err := os.OpenFile(opts.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			m.AddWarningMessage(fmt.Sprintf("Failed to open log file: %v", err), "system")
+		} else {
+			messageQueue.SetLogWriter(logFile)
+		}
+	}
+
+	// Pick up the interrupted installation, or start over without a checkpoint
+	if opts.Resume {
+		if checkpoint, err := loadCheckpoint(checkpointPath()); err != nil {
+			m.AddWarningMessage(fmt.Sprintf("Nothing to resume, starting a new installation: %v", err), "resume")
+		} else {
+			m.resume = &checkpoint
+		}
+	}
+
+	// Only skip the destructive-delete warning when explicitly forced
+	m.overwriteConfirmation = opts.AssumeYes && opts.Force
+
 	// Register routes
 	router.RegisterRoute(Route{
 		Page:     WelcomePage,
 		Title:    "Welcome",
-		Renderer: m.renderWelcomePage,
-		Updater:  m.updateWelcomePage,
+		Renderer: Model.renderWelcomePage,
+		Updater:  Model.updateWelcomePage,
+		Hints:    []string{hint("continue", keyMap.Enter)},
 	})
 
 	router.RegisterRoute(Route{
 		Page:     AURHelperPage,
 		Title:    "AUR Helper",
-		Renderer: m.renderAURHelperPage,
-		Updater:  m.updateAURHelperPage,
+		Renderer: Model.renderAURHelperPage,
+		Updater:  Model.updateAURHelperPage,
+		Hints:    []string{hint("navigate", keyMap.Up, keyMap.Down), hint("select", keyMap.Enter), hint("back", keyMap.Back)},
 	})
 
 	router.RegisterRoute(Route{
 		Page:     PackageCategoriesPage,
 		Title:    "Package Categories",
-		Renderer: m.renderPackageCategoriesPage,
-		Updater:  m.updatePackageCategoriesPage,
+		Renderer: Model.renderPackageCategoriesPage,
+		Updater:  Model.updatePackageCategoriesPage,
+		Hints: []string{
+			hint("navigate", keyMap.Up, keyMap.Down), hint("switch focus", keyMap.Tab), hint("toggle", keyMap.Enter),
+			hint("search", keyMap.Search), hint("extra", keyMap.Extra), hint("tag", keyMap.Tag), hint("install", keyMap.Right),
+		},
 	})
 
 	router.RegisterRoute(Route{
 		Page:     InstallationPage,
 		Title:    "Installation",
-		Renderer: m.renderInstallationPage,
-		Updater:  m.updateInstallationPage,
+		Renderer: Model.renderInstallationPage,
+		Updater:  Model.updateInstallationPage,
+		Hints: []string{
+			"↑↓ choose", "enter confirm", hint("copy output", keyMap.Copy), hint("verbosity", keyMap.Verbosity),
+			hint("notifications", keyMap.Notifications), "ctrl+c ×2 force quit",
+		},
 	})
 
 	router.RegisterRoute(Route{
 		Page:     CompletePage,
 		Title:    "Complete",
-		Renderer: m.renderCompletePage,
-		Updater:  m.updateCompletePage,
+		Renderer: Model.renderCompletePage,
+		Updater:  Model.updateCompletePage,
+		Hints: []string{
+			hint("exit", keyMap.Enter), hint("retry failed", keyMap.Retry), hint("view log", keyMap.View), hint("pager", keyMap.Log),
+			hint("copy output", keyMap.Copy), hint("copy command", keyMap.CopyCommand), hint("bug report", keyMap.Report),
+		},
+	})
+
+	router.RegisterRoute(Route{
+		Page:     FailedPage,
+		Title:    "Installation Failed",
+		Renderer: Model.renderFailedPage,
+		Updater:  Model.updateFailedPage,
+		Hints: []string{
+			hint("choose", keyMap.Left, keyMap.Right), hint("confirm", keyMap.Enter), hint("retry", keyMap.Retry), hint("shell", keyMap.Shell),
+			hint("view log", keyMap.View), hint("copy log", keyMap.Copy), hint("bug report", keyMap.Report),
+		},
+	})
+
+	router.RegisterRoute(Route{
+		Page:     LogPage,
+		Title:    "Install Log",
+		Renderer: Model.renderLogPage,
+		Updater:  Model.updateLogPage,
+		Hints: []string{
+			hint("scroll", keyMap.Up, keyMap.Down), "pgup/pgdn page", hint("filter", keyMap.Tab), hint("search", keyMap.Search),
+			hint("pager", keyMap.Log), hint("back", keyMap.Back),
+		},
 	})
 
 	// Register transitions
-	router.RegisterTransition(WelcomePage, AURHelperPage, func() tea.Cmd {
+	router.RegisterTransition(WelcomePage, AURHelperPage, func(m *Model) tea.Cmd {
 		return m.AddInfoNotification("Welcome", "Please select your preferred AUR helper")
 	})
 
-	router.RegisterTransition(AURHelperPage, PackageCategoriesPage, func() tea.Cmd {
+	router.RegisterTransition(AURHelperPage, WelcomePage, func(m *Model) tea.Cmd {
+		// The AUR helper is set up again when one is picked
+		m.aurHelper = nil
+		m.aurHelperInstalled = false
+		m.skippedAURHelper = ""
+		return nil
+	})
+
+	router.RegisterTransition(AURHelperPage, PackageCategoriesPage, func(m *Model) tea.Cmd {
 		return m.AddInfoNotification("AUR Helper Selected", "Now select the packages you want to install")
 	})
 
-	router.RegisterTransition(PackageCategoriesPage, InstallationPage, func() tea.Cmd {
+	router.RegisterTransition(PackageCategoriesPage, InstallationPage, func(m *Model) tea.Cmd {
 		return tea.Batch(
 			m.AddSuccessNotification("Installation Started", "Installing selected packages"),
 			m.startInstallation(),
 		)
 	})
 
-	router.RegisterTransition(InstallationPage, CompletePage, func() tea.Cmd {
-		return m.AddSuccessNotification("Installation Complete", "All packages have been installed successfully")
+	router.RegisterTransition(WelcomePage, InstallationPage, func(m *Model) tea.Cmd {
+		return tea.Batch(
+			m.AddSuccessNotification("Installation Resumed", "Installing the remaining packages"),
+			m.startInstallation(),
+		)
+	})
+
+	router.RegisterTransition(InstallationPage, CompletePage, func(m *Model) tea.Cmd {
+		return tea.Batch(
+			m.AddSuccessNotification("Installation Complete", "All packages have been installed successfully"),
+			detectHyprlandVersionCmd(),
+		)
 	})
 
 	return m
@@ -245,5 +479,7 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
 		m.tickIndeterminateProgress(),
+		m.runPreflight(),
+		waitForWindowSize(),
 	)
 }
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// installOutput collects output lines produced by a command running outside
+// the bubbletea event loop. The lines are handed back to Update in an
+// InstallProgressMsg instead of being written to the model directly.
+type installOutput struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// Add appends a line of output
+func (o *installOutput) Add(line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lines = append(o.lines, line)
+}
+
+// Lines returns a copy of the collected output
+func (o *installOutput) Lines() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.lines...)
+}
+
+// msgCmd wraps an already computed message in a command
+func msgCmd(msg tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// applyInstallOutput records the output and state carried by a progress message
+func (m *Model) applyInstallOutput(msg InstallProgressMsg) {
+	for _, line := range msg.Output {
+		m.AddMessage(line, msg.Source)
+	}
+
+	if msg.InstalledPackage != "" {
+		m.installedPackages = append(m.installedPackages, msg.InstalledPackage)
+	}
+
+	if msg.FailedPackage != "" {
+		m.failedPackages = append(m.failedPackages, msg.FailedPackage)
+	}
+
+	if msg.SkippedPackage != "" {
+		m.skippedPackages[msg.SkippedPackage] = true
+		m.AddWarningMessage(fmt.Sprintf("Skipping %s: not available in the official repositories", msg.SkippedPackage), "package-install")
+	}
+
+	if msg.PackagesDownloaded {
+		m.packagesDownloaded = true
+	}
+
+	if msg.AURHelperInstalled {
+		m.aurHelperInstalled = true
+	}
+
+	if msg.BackupDir != "" {
+		m.backupDir = msg.BackupDir
+		m.installationPhase = "post-installation"
+	}
+
+	if msg.DotfilesInstalled {
+		m.dotfilesInstalled = true
+		m.installationPhase = "finalize"
+	}
+
+	if msg.CleanupFreed > 0 {
+		m.cleanupFreed += msg.CleanupFreed
+	}
+
+	if len(msg.Instructions) > 0 {
+		m.instructions = msg.Instructions
+	}
+
+	if msg.WallpaperScript != "" {
+		m.wallpaperScript = msg.WallpaperScript
+		m.wallpapers = msg.Wallpapers
+		m.wallpaperIndex = 0
+	}
+}
@@ -0,0 +1,38 @@
+package tui
+
+import "fmt"
+
+// PackageManager installs the AUR helper and the packages. It is implemented
+// by *aur.Helper; tests replace it through Model.newPackageManager so the
+// installation can run without touching the system.
+type PackageManager interface {
+	fmt.Stringer // The AUR helper name, aur.PacmanOnly without one
+
+	// IsPacmanOnly reports whether packages are installed with pacman instead of an AUR helper
+	IsPacmanOnly() bool
+
+	// IsInstalled reports whether the AUR helper is installed
+	IsInstalled() bool
+
+	// Install installs the AUR helper, returning its output
+	Install() ([]string, error)
+
+	// InstallPackages installs packages, returning the output
+	InstallPackages(packages []string) ([]string, error)
+
+	// DownloadPackages downloads the official repository packages among
+	// packages without installing them, returning the output
+	DownloadPackages(packages []string) ([]string, error)
+
+	// InstallCommand returns the shell command that installs packages by hand
+	InstallCommand(packages []string) string
+
+	// DownloadProgress returns the downloaded and total bytes of the packages
+	DownloadProgress() (uint64, uint64)
+
+	// SetSudoPassword sets the password commands are run with through sudo
+	SetSudoPassword(password string)
+
+	// GetSudoPassword returns the password set with SetSudoPassword
+	GetSudoPassword() string
+}
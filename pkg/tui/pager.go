@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pagerClosedMsg is sent when the pager exits and the TUI is restored
+type pagerClosedMsg struct {
+	tempFile string
+	err      error
+}
+
+// pagerCommand returns the user's pager, falling back to less
+func pagerCommand() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	return []string{"less"}
+}
+
+// openLogInPager suspends the TUI and shows the install log in the user's pager
+func (m Model) openLogInPager() tea.Cmd {
+	// Prefer the full log file, otherwise write the message log to a temporary file
+	path := m.options.LogPath
+	tempFile := ""
+	if path == "" {
+		file, err := os.CreateTemp("", "lunaris-installer-log-*.txt")
+		if err != nil {
+			return m.AddErrorNotification("Log Not Opened", err.Error())
+		}
+		_, err = file.WriteString(m.outputText() + "\n")
+		file.Close()
+		if err != nil {
+			os.Remove(file.Name())
+			return m.AddErrorNotification("Log Not Opened", err.Error())
+		}
+		path = file.Name()
+		tempFile = path
+	}
+
+	pager := pagerCommand()
+	cmd := exec.Command(pager[0], append(pager[1:], path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return pagerClosedMsg{tempFile: tempFile, err: err}
+	})
+}
+
+// handlePagerClosed cleans up after the pager exits
+func (m Model) handlePagerClosed(msg pagerClosedMsg) (tea.Model, tea.Cmd) {
+	if msg.tempFile != "" {
+		os.Remove(msg.tempFile)
+	}
+
+	if msg.err != nil {
+		return m, m.AddErrorNotification("Pager Failed", msg.err.Error())
+	}
+	return m, nil
+}
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Phases of the installation shown in the timeline on the complete page
+const (
+	phaseAURHelper        = "AUR Helper"
+	phaseDownload         = "Download"
+	phasePackages         = "Packages"
+	phaseBackup           = "Backup"
+	phaseDotfiles         = "Dotfiles"
+	phasePostInstallation = "Post-Installation"
+)
+
+// Phase statuses
+const (
+	PhaseRunning   = "running"
+	PhaseSucceeded = "succeeded"
+	PhaseFailed    = "failed"
+)
+
+// PhaseResult records how long a phase of the installation took and how it ended
+type PhaseResult struct {
+	Name     string
+	Status   string
+	Error    string
+	Started  time.Time
+	Duration time.Duration
+}
+
+// beginPhase finishes the running phase and starts the named one. Starting
+// the phase that is already last, e.g. when a step is retried, resumes it.
+func (m *Model) beginPhase(name string) {
+	defer m.startPhaseTask(name)
+
+	if n := len(m.phases); n > 0 && m.phases[n-1].Name == name {
+		m.phases[n-1].Status = PhaseRunning
+		m.phases[n-1].Error = ""
+		return
+	}
+
+	m.endPhase(nil)
+	m.phases = append(m.phases, PhaseResult{
+		Name:    name,
+		Status:  PhaseRunning,
+		Started: time.Now(),
+	})
+}
+
+// endPhase finishes the running phase, marking it failed when err is not nil
+func (m *Model) endPhase(err error) {
+	n := len(m.phases)
+	if n == 0 || m.phases[n-1].Status != PhaseRunning {
+		return
+	}
+
+	phase := &m.phases[n-1]
+	phase.Duration = time.Since(phase.Started)
+	phase.Status = PhaseSucceeded
+	if err != nil {
+		phase.Status = PhaseFailed
+		phase.Error = err.Error()
+	}
+	m.finishPhaseTask(phase.Name, err)
+}
+
+// renderPhaseTimeline renders each recorded phase with its status and duration
+func (m Model) renderPhaseTimeline() string {
+	if len(m.phases) == 0 {
+		return ""
+	}
+
+	nameStyle := lipgloss.NewStyle().Width(20)
+
+	lines := make([]string, 0, len(m.phases))
+	for _, phase := range m.phases {
+		var status string
+		switch phase.Status {
+		case PhaseSucceeded:
+			status = SuccessStyle.Render(ui.Symbols.Check)
+		case PhaseFailed:
+			status = ErrorStyle.Render(ui.Symbols.Cross)
+		default:
+			status = m.spinner.View()
+		}
+
+		duration := phase.Duration
+		if phase.Status == PhaseRunning {
+			duration = time.Since(phase.Started)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			status,
+			nameStyle.Render(phase.Name),
+			DimStyle.Render(formatPhaseDuration(duration)),
+		))
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(ui.TextColor).
+		Render(strings.Join(lines, "\n"))
+}
+
+// formatPhaseDuration formats d for the phase timeline, e.g. "2m05s" or "12s"
+func formatPhaseDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
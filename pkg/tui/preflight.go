@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/system"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PreflightMsg carries the results of the startup system checks
+type PreflightMsg struct {
+	DistroName        string
+	UnsupportedDistro bool
+	LiveSession       string
+	HomeError         error // Set when the home directory isn't writable
+	Warnings          []string
+}
+
+// runPreflight returns a command that checks the system before installation
+func (m Model) runPreflight() tea.Cmd {
+	return func() tea.Msg {
+		msg := PreflightMsg{}
+
+		// Check that we're running on an Arch-based distribution with pacman
+		distro, err := system.DetectDistro()
+		if err != nil {
+			msg.DistroName = "unknown"
+			msg.UnsupportedDistro = true
+			msg.Warnings = append(msg.Warnings, fmt.Sprintf("Could not detect the distribution: %v", err))
+		} else {
+			msg.DistroName = distro.Name()
+			if !distro.IsArchBased() {
+				msg.UnsupportedDistro = true
+				msg.Warnings = append(msg.Warnings, fmt.Sprintf("%s is not Arch-based, the installer may not work", distro.Name()))
+			}
+		}
+
+		if _, err := exec.LookPath("pacman"); err != nil {
+			msg.UnsupportedDistro = true
+			msg.Warnings = append(msg.Warnings, "pacman was not found on this system")
+		}
+
+		if err := system.RequireGit(); err != nil {
+			msg.Warnings = append(msg.Warnings, err.Error())
+		}
+
+		// Backups and dotfiles are written to the home directory
+		msg.HomeError = system.CheckHomeWritable()
+
+		// Other desktops bring portal backends that can take over screenshots and screen sharing
+		for _, desktop := range system.InstalledDesktops() {
+			msg.Warnings = append(msg.Warnings, fmt.Sprintf(
+				"%s is installed, its %s backend may conflict with xdg-desktop-portal-hyprland; remove it or set default=hyprland;gtk in ~/.config/xdg-desktop-portal/hyprland-portals.conf if screenshots or screen sharing break",
+				desktop.Name, desktop.Portal))
+		}
+
+		// Installing dotfiles into a running compositor changes its config under it
+		if session := system.LiveSession(); session != "" {
+			msg.LiveSession = session
+			msg.Warnings = append(msg.Warnings, fmt.Sprintf("Running inside a %s session, installing dotfiles may require a logout or reload", session))
+		}
+
+		return msg
+	}
+}
+
+// handlePreflight handles the results of the startup system checks
+func (m Model) handlePreflight(msg PreflightMsg) (tea.Model, tea.Cmd) {
+	m.distroName = msg.DistroName
+	m.unsupportedDistro = msg.UnsupportedDistro
+	m.preflightWarnings = msg.Warnings
+	m.liveSession = msg.LiveSession
+	m.homeError = msg.HomeError
+
+	for _, warning := range msg.Warnings {
+		m.AddWarningMessage(warning, "preflight")
+	}
+
+	// Nothing can be installed without a writable home directory
+	if m.homeError != nil {
+		m.AddErrorMessage(m.homeError.Error(), "preflight")
+		return m, m.AddErrorNotification("Home Directory Not Writable", m.homeError.Error())
+	}
+
+	if m.unsupportedDistro && m.options.AssumeYes {
+		// --yes accepts the unsupported system warning as well
+		m.distroConfirmed = true
+		return m, m.AddWarningNotification("Unsupported System", "Continuing on a non-Arch system because --yes was given")
+	}
+
+	if m.unsupportedDistro {
+		return m, m.AddWarningNotification("Unsupported System", "HyprLuna is designed for Arch Linux")
+	}
+
+	if len(m.preflightWarnings) > 0 {
+		return m, m.AddWarningNotification("Preflight Check", strings.Join(m.preflightWarnings, "\n"))
+	}
+
+	return m, nil
+}
@@ -1,18 +1,21 @@
 package tui
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// Route represents a route in the application
+// Route represents a route in the application. Renderer and Updater are
+// given the current model, e.g. as method expressions like Model.renderWelcomePage.
 type Route struct {
 	Page     Page
 	Title    string
-	Renderer func() string
-	Updater  func(tea.KeyMsg) (tea.Model, tea.Cmd)
+	Renderer func(Model) string
+	Updater  func(Model, tea.KeyMsg) (tea.Model, tea.Cmd)
+	Hints    []string // Key hints shown in the footer while the route is active
 }
 
 // Router manages the application routes
@@ -20,7 +23,7 @@ type Router struct {
 	routes       map[Page]Route
 	history      []Page
 	currentPage  Page
-	transitions  map[Page]map[Page]func() tea.Cmd
+	transitions  map[Page]map[Page]func(*Model) tea.Cmd
 	errorHandler func(error) tea.Cmd
 }
 
@@ -30,7 +33,7 @@ func NewRouter() *Router {
 		routes:      make(map[Page]Route),
 		history:     []Page{},
 		currentPage: WelcomePage,
-		transitions: make(map[Page]map[Page]func() tea.Cmd),
+		transitions: make(map[Page]map[Page]func(*Model) tea.Cmd),
 	}
 }
 
@@ -39,10 +42,11 @@ func (r *Router) RegisterRoute(route Route) {
 	r.routes[route.Page] = route
 }
 
-// RegisterTransition registers a transition between pages
-func (r *Router) RegisterTransition(from, to Page, handler func() tea.Cmd) {
+// RegisterTransition registers a transition between pages. The handler runs
+// on the model being navigated and may change it.
+func (r *Router) RegisterTransition(from, to Page, handler func(*Model) tea.Cmd) {
 	if _, ok := r.transitions[from]; !ok {
-		r.transitions[from] = make(map[Page]func() tea.Cmd)
+		r.transitions[from] = make(map[Page]func(*Model) tea.Cmd)
 	}
 	r.transitions[from][to] = handler
 }
@@ -81,7 +85,7 @@ func (r *Router) Navigate(to Page, m Model) (tea.Model, tea.Cmd) {
 	if fromHandlers, ok := r.transitions[from]; ok {
 		if handler, ok := fromHandlers[to]; ok {
 			// Execute transition handler
-			transitionCmd = handler()
+			transitionCmd = handler(&m)
 		}
 	}
 
@@ -94,7 +98,8 @@ func (r *Router) Navigate(to Page, m Model) (tea.Model, tea.Cmd) {
 	return m, animCmd
 }
 
-// Back navigates back to the previous page with animation
+// Back navigates back to the previous page with animation, running the
+// transition registered from the current page to it
 func (r *Router) Back(m Model) (tea.Model, tea.Cmd) {
 	if len(r.history) == 0 {
 		return m, nil
@@ -115,6 +120,13 @@ func (r *Router) Back(m Model) (tea.Model, tea.Cmd) {
 		return NewPageTransitionMsg(from, previousPage, ui.SlideRight, 300*time.Millisecond)
 	}
 
+	// Let the transition undo what the page it leaves set up
+	if handler, ok := r.transitions[from][previousPage]; ok {
+		if transitionCmd := handler(&m); transitionCmd != nil {
+			return m, tea.Batch(animCmd, transitionCmd)
+		}
+	}
+
 	return m, animCmd
 }
 
@@ -136,5 +148,5 @@ type ErrPageNotFound struct {
 
 // Error returns the error message
 func (e ErrPageNotFound) Error() string {
-	return "page not found: " + string(e.Page)
+	return fmt.Sprintf("page not found: %d", e.Page)
 }
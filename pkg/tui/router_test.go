@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestRouter returns a router with routes for the welcome, AUR helper and
+// package categories pages
+func newTestRouter() *Router {
+	router := NewRouter()
+	for _, page := range []Page{WelcomePage, AURHelperPage, PackageCategoriesPage} {
+		router.RegisterRoute(Route{Page: page})
+	}
+	return router
+}
+
+func TestNavigatePushesHistory(t *testing.T) {
+	router := newTestRouter()
+
+	router.Navigate(AURHelperPage, Model{})
+	router.Navigate(PackageCategoriesPage, Model{})
+
+	if got := router.CurrentPage(); got != PackageCategoriesPage {
+		t.Errorf("CurrentPage() = %d, want %d", got, PackageCategoriesPage)
+	}
+	if want := []Page{WelcomePage, AURHelperPage}; !slices.Equal(router.history, want) {
+		t.Errorf("history = %v, want %v", router.history, want)
+	}
+}
+
+func TestNavigateRunsTransition(t *testing.T) {
+	router := newTestRouter()
+
+	calls := 0
+	router.RegisterTransition(WelcomePage, AURHelperPage, func(m *Model) tea.Cmd {
+		calls++
+		m.aurHelperIndex = 2
+		return nil
+	})
+	router.RegisterTransition(AURHelperPage, WelcomePage, func(m *Model) tea.Cmd {
+		t.Error("transition for another pair of pages ran")
+		return nil
+	})
+
+	next, cmd := router.Navigate(AURHelperPage, Model{})
+
+	if calls != 1 {
+		t.Errorf("transition ran %d times, want 1", calls)
+	}
+	if got := next.(Model).aurHelperIndex; got != 2 {
+		t.Errorf("aurHelperIndex = %d, want the transition to set it to 2", got)
+	}
+	if cmd == nil {
+		t.Error("Navigate() returned no command, want the page transition")
+	}
+}
+
+func TestBackPopsHistory(t *testing.T) {
+	router := newTestRouter()
+	router.Navigate(AURHelperPage, Model{})
+	router.Navigate(PackageCategoriesPage, Model{})
+
+	_, cmd := router.Back(Model{})
+
+	if got := router.CurrentPage(); got != AURHelperPage {
+		t.Errorf("CurrentPage() = %d, want %d", got, AURHelperPage)
+	}
+	if want := []Page{WelcomePage}; !slices.Equal(router.history, want) {
+		t.Errorf("history = %v, want %v", router.history, want)
+	}
+	if cmd == nil {
+		t.Error("Back() returned no command, want the page transition")
+	}
+}
+
+func TestBackWithoutHistory(t *testing.T) {
+	router := newTestRouter()
+
+	_, cmd := router.Back(Model{})
+
+	if got := router.CurrentPage(); got != WelcomePage {
+		t.Errorf("CurrentPage() = %d, want %d", got, WelcomePage)
+	}
+	if len(router.history) != 0 {
+		t.Errorf("history = %v, want it empty", router.history)
+	}
+	if cmd != nil {
+		t.Error("Back() returned a command without history")
+	}
+}
+
+func TestNavigateUnregisteredPage(t *testing.T) {
+	router := newTestRouter()
+
+	var handled error
+	router.SetErrorHandler(func(err error) tea.Cmd {
+		handled = err
+		return nil
+	})
+
+	router.Navigate(FailedPage, Model{})
+
+	var notFound ErrPageNotFound
+	if !errors.As(handled, &notFound) || notFound.Page != FailedPage {
+		t.Errorf("error handler got %v, want ErrPageNotFound for page %d", handled, FailedPage)
+	}
+	if got := router.CurrentPage(); got != WelcomePage {
+		t.Errorf("CurrentPage() = %d, want %d", got, WelcomePage)
+	}
+	if len(router.history) != 0 {
+		t.Errorf("history = %v, want it empty", router.history)
+	}
+}
+
+func TestNavigateUnregisteredPageWithoutErrorHandler(t *testing.T) {
+	router := newTestRouter()
+
+	_, cmd := router.Navigate(FailedPage, Model{})
+
+	if cmd != nil {
+		t.Error("Navigate() returned a command for an unregistered page")
+	}
+	if got := router.CurrentPage(); got != WelcomePage {
+		t.Errorf("CurrentPage() = %d, want %d", got, WelcomePage)
+	}
+}
+
+func TestGetRoute(t *testing.T) {
+	router := NewRouter()
+	router.RegisterRoute(Route{Page: AURHelperPage, Title: "AUR Helper"})
+
+	route, ok := router.GetRoute(AURHelperPage)
+	if !ok || route.Title != "AUR Helper" {
+		t.Errorf("GetRoute(AURHelperPage) = %+v, %v, want the registered route", route, ok)
+	}
+
+	if _, ok := router.GetRoute(CompletePage); ok {
+		t.Error("GetRoute(CompletePage) found a route that wasn't registered")
+	}
+}
+
+func TestBackRunsTransition(t *testing.T) {
+	router := newTestRouter()
+	router.Navigate(AURHelperPage, Model{})
+
+	calls := 0
+	router.RegisterTransition(AURHelperPage, WelcomePage, func(m *Model) tea.Cmd {
+		calls++
+		m.aurHelperIndex = 1
+		return nil
+	})
+
+	next, _ := router.Back(Model{})
+
+	if calls != 1 {
+		t.Errorf("transition ran %d times, want 1", calls)
+	}
+	if got := next.(Model).aurHelperIndex; got != 1 {
+		t.Errorf("aurHelperIndex = %d, want the transition to set it to 1", got)
+	}
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"strings"
 
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -29,6 +30,14 @@ func (m Model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.searchFocused = false
 		return m, nil
 
+	case tea.KeyTab:
+		// Accept the inline completion
+		if completion := m.searchCompletion(); completion != "" {
+			m.searchQuery += completion
+			m.updateFilteredOptions()
+		}
+		return m, nil
+
 	default:
 		// Add character to search query
 		if msg.Type == tea.KeyRunes {
@@ -65,6 +74,24 @@ func (m *Model) updateFilteredOptions() {
 	}
 }
 
+// searchCompletion returns the remainder of the first option name that starts with the search query
+func (m Model) searchCompletion() string {
+	if m.searchQuery == "" {
+		return ""
+	}
+
+	// Compare and cut runes, lowercasing can change the length in bytes
+	query := []rune(m.searchQuery)
+	for _, option := range m.filteredOptions {
+		runes := []rune(option)
+		if len(runes) > len(query) && ui.HasPrefixFold(runes, query) {
+			return string(runes[len(query):])
+		}
+	}
+
+	return ""
+}
+
 // containsIgnoreCase checks if a string contains another string, ignoring case
 func containsIgnoreCase(s, substr string) bool {
 	s, substr = strings.ToLower(s), strings.ToLower(substr)
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// shellClosedMsg is sent when the shell exits and the TUI is restored
+type shellClosedMsg struct {
+	err error
+}
+
+// shellExec runs the user's shell after telling them how to get back
+type shellExec struct {
+	*exec.Cmd
+	banner string
+}
+
+// Run prints the banner and runs the shell
+func (s *shellExec) Run() error {
+	fmt.Fprintln(s.Stdout, s.banner)
+	return s.Cmd.Run()
+}
+
+// SetStdin sets the shell's standard input
+func (s *shellExec) SetStdin(r io.Reader) {
+	if s.Stdin == nil {
+		s.Stdin = r
+	}
+}
+
+// SetStdout sets the shell's standard output
+func (s *shellExec) SetStdout(w io.Writer) {
+	if s.Stdout == nil {
+		s.Stdout = w
+	}
+}
+
+// SetStderr sets the shell's standard error
+func (s *shellExec) SetStderr(w io.Writer) {
+	if s.Stderr == nil {
+		s.Stderr = w
+	}
+}
+
+// shellCommand returns the user's shell, falling back to /bin/sh
+func shellCommand() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// openShell suspends the TUI and drops into a shell to fix a failure by hand
+func (m Model) openShell() tea.Cmd {
+	shell := &shellExec{
+		Cmd:    exec.Command(shellCommand()),
+		banner: fmt.Sprintf("Installation failed: %s\nFix the problem, then type exit to return to the installer.", m.errorMessage),
+	}
+	return tea.Exec(shell, func(err error) tea.Msg {
+		return shellClosedMsg{err: err}
+	})
+}
+
+// handleShellClosed offers to retry the failed step once the shell exits
+func (m Model) handleShellClosed(msg shellClosedMsg) (tea.Model, tea.Cmd) {
+	// The shell's exit status is whatever the last command returned, so only
+	// failing to start it is an error
+	var execErr *exec.ExitError
+	if msg.err != nil && !errors.As(msg.err, &execErr) {
+		return m, m.AddErrorNotification("Shell Failed", msg.err.Error())
+	}
+
+	if m.failedStep == "" {
+		return m, nil
+	}
+	m.retryStep = m.failedStep
+	return m, m.AddInfoNotification("Back From Shell", "Press r to retry the failed step")
+}
@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// smokeTimeout is how long the smoke test waits for each step of the installation
+const smokeTimeout = 10 * time.Second
+
+// fakePackageManager pretends to install packages, recording them
+type fakePackageManager struct {
+	name string
+
+	mu        sync.Mutex
+	installed []string
+	password  string
+}
+
+func (f *fakePackageManager) String() string     { return f.name }
+func (f *fakePackageManager) IsPacmanOnly() bool { return false }
+func (f *fakePackageManager) IsInstalled() bool  { return true }
+
+func (f *fakePackageManager) Install() ([]string, error) {
+	return []string{"AUR helper already installed"}, nil
+}
+
+func (f *fakePackageManager) InstallPackages(packages []string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.installed = append(f.installed, packages...)
+	return []string{"Packages installed successfully"}, nil
+}
+
+func (f *fakePackageManager) DownloadPackages(packages []string) ([]string, error) {
+	return []string{"No packages to download"}, nil
+}
+
+func (f *fakePackageManager) InstallCommand(packages []string) string {
+	return f.name + " -S " + strings.Join(packages, " ")
+}
+
+func (f *fakePackageManager) DownloadProgress() (uint64, uint64) { return 0, 0 }
+
+func (f *fakePackageManager) SetSudoPassword(password string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.password = password
+}
+
+func (f *fakePackageManager) GetSudoPassword() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.password
+}
+
+// modelState is the model after an update, with the page it was on
+type modelState struct {
+	Model
+	page Page
+}
+
+// observedModel passes the model to the test after every update
+type observedModel struct {
+	Model
+	states chan<- modelState
+}
+
+func (o observedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := o.Model.Update(msg)
+	switch next := next.(type) {
+	case Model:
+		o.Model = next
+	case *Model:
+		o.Model = *next
+	}
+
+	// Never block the program, the states are buffered generously
+	select {
+	case o.states <- modelState{Model: o.Model, page: o.router.CurrentPage()}:
+	default:
+	}
+	return o, cmd
+}
+
+// waitFor returns the first state done reports true for, failing the test
+// when the installation fails or takes longer than smokeTimeout
+func waitFor(t *testing.T, states <-chan modelState, what string, done func(modelState) bool) modelState {
+	t.Helper()
+	timeout := time.After(smokeTimeout)
+	var last modelState
+	for {
+		select {
+		case state := <-states:
+			if state.errorMessage != "" {
+				t.Fatalf("installation failed while waiting for %s: %s", what, state.errorMessage)
+			}
+			if done(state) {
+				return state
+			}
+			last = state
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s, last on page %d in phase %q at %q", what, last.page, last.installPhase, last.currentStep)
+		}
+	}
+}
+
+// TestInstallationSmoke runs the whole installation headlessly with a fake
+// package manager and a temporary home directory, from the welcome page to
+// the complete page
+func TestInstallationSmoke(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+
+	// Install the dotfiles from a checkout in place, without git or a network
+	repo := filepath.Join(home, "HyprLuna")
+	for _, path := range config.RequiredConfigPaths {
+		if err := os.MkdirAll(filepath.Join(repo, path), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".config", "hypr", "hyprland.conf"), []byte("# HyprLuna\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := config.DefaultOptions()
+	opts.UseExisting = true
+	opts.Animations = false
+	opts.Services = nil
+
+	fake := &fakePackageManager{}
+	m := NewModelWithOptions(opts)
+	m.newPackageManager = func(name string) PackageManager {
+		fake.name = name
+		return fake
+	}
+
+	states := make(chan modelState, 4096)
+	p := tea.NewProgram(observedModel{Model: m, states: states},
+		tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutRenderer(), tea.WithoutSignalHandler())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run()
+	}()
+	defer func() {
+		// Kill can leave the event loop stuck sending a command
+		p.Quit()
+		<-done
+	}()
+	p.Send(tea.WindowSizeMsg{Width: 100, Height: 40})
+
+	// Welcome, going on even if the test doesn't run on Arch
+	state := waitFor(t, states, "the preflight checks", func(s modelState) bool { return s.distroName != "" })
+	if state.unsupportedDistro {
+		p.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	}
+	p.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// AUR helper, picking the first one
+	waitFor(t, states, "the AUR helper page", func(s modelState) bool { return s.page == AURHelperPage })
+	p.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Packages, installing the default selection
+	waitFor(t, states, "the package page", func(s modelState) bool { return s.page == PackageCategoriesPage })
+	p.Send(tea.KeyMsg{Type: tea.KeyRight})
+	waitFor(t, states, "the install confirmation", func(s modelState) bool { return s.confirmingInstall })
+	p.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Install, entering the sudo password
+	waitFor(t, states, "the password prompt", func(s modelState) bool { return s.awaitingPassword })
+	p.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("secret")})
+	p.Send(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Answer each prompt once: install the dotfiles, without a backup and
+	// without enabling services
+	answered := map[string]bool{}
+	for {
+		state = waitFor(t, states, "a prompt or the complete page", func(s modelState) bool {
+			switch s.installPhase {
+			case "dotfiles_confirmation", "backup_confirmation", "overwrite_confirmation", "services_confirmation":
+				return !answered[s.installPhase]
+			}
+			return s.page == CompletePage
+		})
+		if state.page == CompletePage {
+			break
+		}
+
+		answered[state.installPhase] = true
+		if state.installPhase == "dotfiles_confirmation" {
+			p.Send(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		p.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	if len(state.failedPackages) > 0 {
+		t.Errorf("failedPackages = %v, want none", state.failedPackages)
+	}
+	if !state.dotfilesInstalled {
+		t.Error("dotfilesInstalled = false on the complete page, want true")
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "hypr", "hyprland.conf")); err != nil {
+		t.Errorf("dotfiles weren't copied: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.password != "secret" {
+		t.Errorf("sudo password = %q, want the one entered", fake.password)
+	}
+	for _, pkg := range config.BasePackages {
+		if !slices.Contains(fake.installed, pkg) {
+			t.Errorf("base package %s wasn't installed", pkg)
+		}
+	}
+}
@@ -7,110 +7,144 @@ import (
 
 // Colors - Tokyo Night theme (imported from ui package)
 var (
-	primaryColor    = ui.PrimaryColor
-	secondaryColor  = ui.SecondaryColor
-	successColor    = ui.SuccessColor
-	warningColor    = ui.WarningColor
-	errorColor      = ui.ErrorColor
-	textColor       = ui.TextColor
-	dimmedColor     = ui.DimmedColor
-	accentColor     = ui.AccentColor
-	backgroundColor = ui.BackgroundColor
+	primaryColor    lipgloss.Color
+	secondaryColor  lipgloss.Color
+	successColor    lipgloss.Color
+	warningColor    lipgloss.Color
+	errorColor      lipgloss.Color
+	textColor       lipgloss.Color
+	dimmedColor     lipgloss.Color
+	accentColor     lipgloss.Color
+	backgroundColor lipgloss.Color
 )
 
 // Styles
 var (
+	PageContainer  lipgloss.Style
+	ContentBox     lipgloss.Style
+	BaseStyle      lipgloss.Style
+	TitleStyle     lipgloss.Style
+	SubtitleStyle  lipgloss.Style
+	BoxStyle       lipgloss.Style
+	ButtonStyle    lipgloss.Style
+	SelectionStyle lipgloss.Style
+	HighlightStyle lipgloss.Style
+	InfoStyle      lipgloss.Style
+	WarningStyle   lipgloss.Style
+	ErrorStyle     lipgloss.Style
+	SuccessStyle   lipgloss.Style
+	DimStyle       lipgloss.Style
+	FocusedStyle   lipgloss.Style
+	UnfocusedStyle lipgloss.Style
+)
+
+func init() {
+	loadStyles()
+}
+
+// loadStyles builds the styles from the ui colors, so it runs again when the palette changes
+func loadStyles() {
+	primaryColor = ui.PrimaryColor
+	secondaryColor = ui.SecondaryColor
+	successColor = ui.SuccessColor
+	warningColor = ui.WarningColor
+	errorColor = ui.ErrorColor
+	textColor = ui.TextColor
+	dimmedColor = ui.DimmedColor
+	accentColor = ui.AccentColor
+	backgroundColor = ui.BackgroundColor
+
 	// Container for entire pages
 	PageContainer = lipgloss.NewStyle().
-			Align(lipgloss.Center).
-			AlignVertical(lipgloss.Center)
+		Align(lipgloss.Center).
+		AlignVertical(lipgloss.Center)
 
 	// Content box for sections
 	ContentBox = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(1, 2).
-			Align(lipgloss.Center)
+		Border(ui.Border()).
+		BorderForeground(primaryColor).
+		Padding(1, 2).
+		Align(lipgloss.Center)
 	// Base text style
 	BaseStyle = lipgloss.NewStyle().
-			Foreground(textColor)
+		Foreground(textColor)
 
 	// Title style
 	TitleStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true).
-			Underline(true).
-			Padding(1, 0, 0, 0)
+		Foreground(primaryColor).
+		Bold(true).
+		Underline(true).
+		Padding(1, 0, 0, 0)
 
 	// Subtitle style
 	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			Italic(true).
-			Padding(0, 0, 1, 0)
+		Foreground(secondaryColor).
+		Italic(true).
+		Padding(0, 0, 1, 0)
 
 	// Box style
 	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(accentColor).
-			Padding(1, 2)
+		Border(ui.Border()).
+		BorderForeground(accentColor).
+		Padding(1, 2)
 
 	// Button style
 	ButtonStyle = lipgloss.NewStyle().
-			Foreground(backgroundColor).
-			Background(primaryColor).
-			Bold(true).
-			Padding(0, 3).
-			Margin(1, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(accentColor)
+		Foreground(backgroundColor).
+		Background(primaryColor).
+		Bold(true).
+		Padding(0, 3).
+		Margin(1, 1).
+		Border(ui.Border()).
+		BorderForeground(accentColor)
 
 	// Selection style
 	SelectionStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(true)
+		Foreground(accentColor).
+		Bold(true)
 
 	// Highlight style
 	HighlightStyle = lipgloss.NewStyle().
-			Background(primaryColor).
-			Foreground(backgroundColor).
-			Bold(true).
-			Padding(0, 1)
+		Background(primaryColor).
+		Foreground(backgroundColor).
+		Bold(true).
+		Padding(0, 1)
 
 	// Info style
 	InfoStyle = lipgloss.NewStyle().
-			Foreground(textColor)
+		Foreground(textColor)
 
 	// Warning style
 	WarningStyle = lipgloss.NewStyle().
-			Foreground(warningColor).
-			Bold(true)
+		Foreground(warningColor).
+		Bold(true)
 
 	// Error style
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(errorColor).
-			Bold(true)
+		Foreground(errorColor).
+		Bold(true)
 
 	// Success style
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(successColor).
-			Bold(true)
+		Foreground(successColor).
+		Bold(true)
 
 	// Dim style
 	DimStyle = lipgloss.NewStyle().
-			Foreground(dimmedColor)
+		Foreground(dimmedColor)
 
 	// Focused style for inputs
 	FocusedStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(1, 2)
+		Border(ui.Border()).
+		BorderForeground(primaryColor).
+		Padding(1, 2)
 
 	// Unfocused style for inputs
 	UnfocusedStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(dimmedColor).
-			Padding(1, 2)
-)
+		Border(ui.Border()).
+		BorderForeground(dimmedColor).
+		Padding(1, 2)
+}
 
 // RenderCheckbox renders a checkbox
 func RenderCheckbox(checked bool) string {
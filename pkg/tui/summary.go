@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// InstallResult is the machine-readable outcome of an installer run
+type InstallResult struct {
+	Success           bool     `json:"success"`
+	Error             string   `json:"error,omitempty"`
+	AURHelper         string   `json:"aur_helper,omitempty"`
+	InstalledPackages []string `json:"installed_packages"`
+	FailedPackages    []string `json:"failed_packages"`
+	SkippedPackages   []string `json:"skipped_packages"`
+	ExcludedPackages  []string `json:"excluded_packages"`
+	InstallCommand    string   `json:"install_command,omitempty"`
+	DotfilesInstalled bool     `json:"dotfiles_installed"`
+	BackupDir         string   `json:"backup_dir,omitempty"`
+	Strict            bool     `json:"strict"`
+	StartedAt         string   `json:"started_at,omitempty"`
+	DurationSeconds   float64  `json:"duration_seconds"`
+	DiskUsedBytes     int64    `json:"disk_used_bytes,omitempty"`
+	CleanupFreedBytes uint64   `json:"cleanup_freed_bytes,omitempty"`
+}
+
+// defaultSummaryPath returns the path the summary is written to when none is configured
+func defaultSummaryPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "lunaris-installer-summary.json")
+	}
+	return filepath.Join(cacheDir, "lunaris-installer", "summary.json")
+}
+
+// installResult builds the install result from the current model state
+func (m Model) installResult(err error) InstallResult {
+	result := InstallResult{
+		Success:           err == nil,
+		InstalledPackages: append([]string{}, m.installedPackages...),
+		FailedPackages:    append([]string{}, m.failedPackages...),
+		SkippedPackages:   []string{},
+		ExcludedPackages:  append([]string{}, m.excluded...),
+		InstallCommand:    m.installCommand(m.queuedPackages),
+		DotfilesInstalled: m.dotfilesInstalled,
+		BackupDir:         m.backupDir,
+		Strict:            m.options.Strict,
+		CleanupFreedBytes: m.cleanupFreed,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if m.aurHelper != nil {
+		result.AURHelper = m.aurHelper.String()
+	}
+
+	for pkg, skipped := range m.skippedPackages {
+		if skipped {
+			result.SkippedPackages = append(result.SkippedPackages, pkg)
+		}
+	}
+	sort.Strings(result.SkippedPackages)
+
+	if len(m.diskBefore) > 0 && len(m.diskAfter) > 0 {
+		result.DiskUsedBytes = diskUsed(m.diskBefore, m.diskAfter)
+	}
+
+	if !m.startTime.IsZero() {
+		result.StartedAt = m.startTime.Format(time.RFC3339)
+		result.DurationSeconds = time.Since(m.startTime).Seconds()
+	}
+
+	return result
+}
+
+// writeSummary writes the install result as JSON to path
+func writeSummary(result InstallResult, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create summary directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	return nil
+}
+
+// writeSummaryCmd returns a command that writes the exit summary file
+func (m Model) writeSummaryCmd(err error) tea.Cmd {
+	result := m.installResult(err)
+	path := m.options.SummaryPath
+	if path == "" {
+		path = defaultSummaryPath()
+	}
+
+	return func() tea.Msg {
+		if err := writeSummary(result, path); err != nil {
+			return NotificationMsg{
+				Type:    ui.WarningNotification,
+				Title:   "Summary Not Written",
+				Message: err.Error(),
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// taggedOption locates an option matching the tag filter
+type taggedOption struct {
+	category int
+	option   int
+}
+
+// taggedOptions returns the options of every category that have the tag filter
+func (m Model) taggedOptions() []taggedOption {
+	var options []taggedOption
+	for i, category := range m.categories {
+		for j, option := range category.Options {
+			if slices.Contains(option.Tags, m.tagFilter) {
+				options = append(options, taggedOption{category: i, option: j})
+			}
+		}
+	}
+	return options
+}
+
+// cycleTagFilter switches the tag filter to the next tag, and back to
+// showing every category after the last one
+func (m *Model) cycleTagFilter() tea.Cmd {
+	tags := config.Tags(m.categories)
+	if len(tags) == 0 {
+		return m.AddInfoNotification("No Tags", "None of the package options have tags")
+	}
+
+	next := 0
+	if i := slices.Index(tags, m.tagFilter); i >= 0 {
+		next = i + 1
+	}
+	m.tagIndex = 0
+
+	if next == len(tags) {
+		m.tagFilter = ""
+		return nil
+	}
+	m.tagFilter = tags[next]
+	return nil
+}
+
+// updateTagFilter moves through and toggles the options matching the tag filter
+func (m Model) updateTagFilter(msg tea.KeyMsg) (Model, tea.Cmd) {
+	options := m.taggedOptions()
+	if len(options) == 0 {
+		return m, nil
+	}
+	m.tagIndex = max(0, min(m.tagIndex, len(options)-1))
+
+	switch {
+	case key.Matches(msg, m.keyMap.Up):
+		m.tagIndex = max(0, m.tagIndex-1)
+	case key.Matches(msg, m.keyMap.Down):
+		m.tagIndex = min(len(options)-1, m.tagIndex+1)
+	case key.Matches(msg, m.keyMap.Enter):
+		selected := options[m.tagIndex]
+		return m, m.toggleOption(selected.category, selected.option)
+	}
+	return m, nil
+}
+
+// renderTaggedOptions renders the options matching the tag filter as one list
+func (m Model) renderTaggedOptions() string {
+	options := m.taggedOptions()
+	if len(options) == 0 {
+		return InfoStyle.Render(fmt.Sprintf("No options tagged %q", m.tagFilter))
+	}
+
+	lines := make([]string, 0, len(options)+2)
+	lines = append(lines, SelectionStyle.Render(fmt.Sprintf("Tagged %s", m.tagFilter)), "")
+	for i, tagged := range options {
+		category := m.categories[tagged.category]
+		option := category.Options[tagged.option]
+
+		optionStyle := BaseStyle
+		if i == m.tagIndex {
+			optionStyle = SelectionStyle.Copy().Bold(true)
+		}
+
+		checked := slices.Contains(m.selectedOptions[category.Name], option.Name)
+		name := fmt.Sprintf("%s %s%s%s%s %s", RenderCheckbox(checked), option.Name, stabilityBadge(option), buildTimeHint(option), changedMarker(option, checked), DimStyle.Render("("+category.Name+")"))
+		lines = append(lines, "  "+optionStyle.Render(name))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -1,8 +1,11 @@
 package tui
 
 import (
+	"slices"
 	"time"
 
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/system"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -35,6 +38,25 @@ func (m *Model) AddTask(name string, total int) {
 	m.tasks = append(m.tasks, task)
 }
 
+// startPhaseTask shows an installation phase as a running task, adding the
+// task the first time the phase starts
+func (m *Model) startPhaseTask(name string) {
+	if !slices.ContainsFunc(m.tasks, func(task ui.TaskProgress) bool { return task.Name == name }) {
+		m.AddTask(name, 0)
+	}
+	m.handleTaskMsg(TaskMsg{Name: name, Status: "In progress", IsActive: true})
+}
+
+// finishPhaseTask marks the task of an installation phase as done, or as
+// failed when err is not nil
+func (m *Model) finishPhaseTask(name string, err error) {
+	if err != nil {
+		m.handleTaskMsg(TaskMsg{Name: name, Status: "Failed", HasError: true})
+		return
+	}
+	m.handleTaskMsg(TaskMsg{Name: name, Status: "Done", IsDone: true})
+}
+
 // UpdateTask updates a task in the model
 func (m *Model) UpdateTask(name string, progress int, status string, isActive bool, isDone bool, hasError bool) tea.Cmd {
 	return func() tea.Msg {
@@ -94,9 +116,9 @@ func (m *Model) handleTaskMsg(msg TaskMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// renderTasks renders the task list
+// renderTasks renders the task list, spinning the active tasks
 func (m Model) renderTasks() string {
-	return ui.TaskList(m.tasks, m.width)
+	return ui.TaskList(m.tasks, m.width, m.spinner.View())
 }
 
 // updateIndeterminateProgress updates the indeterminate progress position
@@ -117,5 +139,27 @@ type indeterminateProgressTickMsg struct{}
 // handleIndeterminateProgressTick handles indeterminate progress ticks
 func (m Model) handleIndeterminateProgressTick() (tea.Model, tea.Cmd) {
 	m.indeterminatePos++
+
+	// Clones run outside the event loop, so their progress is polled
+	stage, percent, cloning := system.CloneProgress()
+	if cloning && (stage != m.cloneStage || percent != m.clonePercent) {
+		m.lastActivity = time.Now()
+	}
+	m.cloneStage, m.clonePercent, m.cloning = stage, percent, cloning
+
+	// As do package downloads, whose progress changes while a package installs
+	if m.aurHelper != nil {
+		if downloaded, total := m.aurHelper.DownloadProgress(); total > 0 {
+			if downloaded != m.downloadedBytes || total != m.downloadTotal {
+				m.lastActivity = time.Now()
+			}
+			m.downloadedBytes, m.downloadTotal = downloaded, total
+		}
+	}
+
+	// Progress and output of a long step count as activity for the watchdog
+	if last := aur.LastOutput(); last.After(m.lastActivity) {
+		m.lastActivity = last
+	}
 	return m, m.tickIndeterminateProgress()
 }
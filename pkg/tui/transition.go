@@ -9,8 +9,9 @@ import (
 
 // handlePageTransition handles page transitions with animation
 func (m Model) handlePageTransition(msg PageTransitionMsg) (tea.Model, tea.Cmd) {
-	// Skip animation if width is not set yet (window size not received)
-	if m.width == 0 {
+	// Skip animation if width is not set yet (window size not received) or animations are disabled.
+	// Inline rendering has no full screen to animate on.
+	if m.width == 0 || !m.options.Animations || m.options.NoAltScreen {
 		return m, nil
 	}
 
@@ -21,13 +22,13 @@ func (m Model) handlePageTransition(msg PageTransitionMsg) (tea.Model, tea.Cmd)
 	// Render the previous page content
 	prevRoute, ok := m.router.GetRoute(msg.FromPage)
 	if ok {
-		m.prevContent = prevRoute.Renderer()
+		m.prevContent = prevRoute.Renderer(m)
 	}
 
 	// Render the next page content
 	nextRoute, ok := m.router.GetRoute(msg.ToPage)
 	if ok {
-		m.nextContent = nextRoute.Renderer()
+		m.nextContent = nextRoute.Renderer(m)
 	}
 
 	// Create a command to update the animation
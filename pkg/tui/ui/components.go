@@ -33,7 +33,7 @@ func Container(content string, width, height int) string {
 // Box creates a box with the given content
 func Box(content string, width int, title string) string {
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(Border()).
 		BorderForeground(PrimaryColor).
 		Padding(1, 2).
 		Width(width)
@@ -56,7 +56,7 @@ func Box(content string, width int, title string) string {
 		titleWidth := lipgloss.Width(renderedTitle)
 
 		// Calculate the width of the box
-		boxWidth := width - 4 // Subtract padding and borders
+		boxWidth := ClampWidth(width - 4) // Subtract padding and borders
 
 		// Calculate the left padding
 		leftPadding := (boxWidth - titleWidth) / 2
@@ -65,10 +65,10 @@ func Box(content string, width int, title string) string {
 		}
 
 		// Create the title line (unused for now, but could be used for custom title rendering)
-		_ = strings.Repeat("─", leftPadding) + " " + renderedTitle + " " + strings.Repeat("─", boxWidth-titleWidth-leftPadding-2)
+		_ = strings.Repeat("─", leftPadding) + " " + renderedTitle + " " + strings.Repeat("─", max(boxWidth-titleWidth-leftPadding-2, 0))
 
 		// Render the box with the title
-		return boxStyle.Border(lipgloss.RoundedBorder()).
+		return boxStyle.Border(Border()).
 			BorderForeground(PrimaryColor).
 			BorderTop(false).
 			Render(content)
@@ -102,7 +102,7 @@ func Button(content string, selected bool) string {
 	style := lipgloss.NewStyle().
 		Padding(0, 3).
 		Margin(1, 1).
-		Border(lipgloss.RoundedBorder()).
+		Border(Border()).
 		BorderForeground(AccentColor)
 
 	if selected {
@@ -138,11 +138,11 @@ func Checkbox(checked bool, label string, selected bool) string {
 		checkbox = lipgloss.NewStyle().
 			Foreground(SuccessColor).
 			Bold(true).
-			Render("[✓]")
+			Render(Symbols.CheckboxOn)
 	} else {
 		checkbox = lipgloss.NewStyle().
 			Foreground(DimmedColor).
-			Render("[ ]")
+			Render(Symbols.CheckboxOff)
 	}
 
 	labelStyle := lipgloss.NewStyle()
@@ -160,6 +160,8 @@ func Checkbox(checked bool, label string, selected bool) string {
 
 // ProgressBar creates a progress bar
 func ProgressBar(width, percent int) string {
+	width = max(width, 0)
+
 	// Ensure percent is between 0 and 100
 	if percent < 0 {
 		percent = 0
@@ -171,12 +173,12 @@ func ProgressBar(width, percent int) string {
 	filledWidth := (width * percent) / 100
 
 	// Create the filled and empty portions with more visually appealing characters
-	filled := strings.Repeat("█", filledWidth)
-	empty := strings.Repeat("▒", width-filledWidth)
+	filled := strings.Repeat(Symbols.BarFilled, filledWidth)
+	empty := strings.Repeat(Symbols.BarEmpty, width-filledWidth)
 
 	// Add a border to the progress bar
 	bar := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(Border()).
 		BorderForeground(AccentColor).
 		Padding(0, 1).
 		Render(
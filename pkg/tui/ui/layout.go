@@ -4,6 +4,15 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+// MinWidth is the narrowest width content is laid out at, so widths computed
+// from a tiny terminal never go negative
+const MinWidth = 10
+
+// ClampWidth returns width, but at least MinWidth
+func ClampWidth(width int) int {
+	return max(width, MinWidth)
+}
+
 // Layout represents a page layout
 type Layout struct {
 	width  int
@@ -63,7 +72,7 @@ func (l *Layout) HeaderBodyFooter(header, body, footer string) string {
 // SidebarContent creates a sidebar-content layout
 func (l *Layout) SidebarContent(sidebar, content string, sidebarWidth int) string {
 	// Calculate the width of the content
-	contentWidth := l.width - sidebarWidth
+	contentWidth := ClampWidth(l.width - sidebarWidth)
 
 	// Render the sidebar and content with the calculated widths
 	renderedSidebar := lipgloss.NewStyle().
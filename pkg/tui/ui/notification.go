@@ -55,7 +55,7 @@ func (n *Notification) Dismiss() {
 // RenderNotification renders a notification
 func RenderNotification(notification Notification, width int) string {
 	// Calculate notification width
-	notifWidth := min(width-10, 60)
+	notifWidth := ClampWidth(min(width-10, 60))
 
 	// Create styles based on notification type
 	var (
@@ -68,19 +68,19 @@ func RenderNotification(notification Notification, width int) string {
 	case InfoNotification:
 		borderColor = PrimaryColor
 		titleColor = PrimaryColor
-		icon = "ℹ"
+		icon = Symbols.Info
 	case SuccessNotification:
 		borderColor = SuccessColor
 		titleColor = SuccessColor
-		icon = "✓"
+		icon = Symbols.Check
 	case WarningNotification:
 		borderColor = WarningColor
 		titleColor = WarningColor
-		icon = "⚠"
+		icon = Symbols.Warning
 	case ErrorNotification:
 		borderColor = ErrorColor
 		titleColor = ErrorColor
-		icon = "✗"
+		icon = Symbols.Cross
 	}
 
 	// Create title style
@@ -94,7 +94,7 @@ func RenderNotification(notification Notification, width int) string {
 
 	// Create notification box
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(Border()).
 		BorderForeground(borderColor).
 		Padding(1, 2).
 		Width(notifWidth)
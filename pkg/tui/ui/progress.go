@@ -9,6 +9,8 @@ import (
 
 // SimpleProgressBar creates a simple progress bar
 func SimpleProgressBar(width, percent int) string {
+	width = max(width, 0)
+
 	// Calculate the width of the filled portion
 	filledWidth := int(float64(width) * float64(percent) / 100.0)
 
@@ -24,8 +26,8 @@ func SimpleProgressBar(width, percent int) string {
 	emptyWidth := width - filledWidth
 
 	// Create the filled and empty portions
-	filled := strings.Repeat("█", filledWidth)
-	empty := strings.Repeat("░", emptyWidth)
+	filled := strings.Repeat(Symbols.BarFilled, filledWidth)
+	empty := strings.Repeat(Symbols.BarEmpty, emptyWidth)
 
 	// Style the filled portion
 	filledStyle := lipgloss.NewStyle().
@@ -78,6 +80,8 @@ func ProgressIndicator(width, percent int, label string) string {
 
 // IndeterminateProgressBar creates an indeterminate progress bar
 func IndeterminateProgressBar(width int, position int) string {
+	width = max(width, 1)
+
 	// Calculate the position of the indicator
 	pos := position % (width * 2)
 	if pos > width {
@@ -85,10 +89,10 @@ func IndeterminateProgressBar(width int, position int) string {
 	}
 
 	// Create the bar
-	bar := strings.Repeat("░", width)
+	bar := strings.Repeat(Symbols.BarEmpty, width)
 
 	// Insert the indicator
-	indicator := "█"
+	indicator := Symbols.BarFilled
 	if pos < width {
 		bar = bar[:pos] + indicator + bar[pos+1:]
 	}
@@ -108,7 +112,7 @@ func IndeterminateProgressBar(width int, position int) string {
 		styledPos := pos
 		for i := 0; i < pos; i++ {
 			// Add the length of ANSI escape sequences for each character
-			styledPos += len(barStyle.Render("░")) - 1
+			styledPos += len(barStyle.Render(Symbols.BarEmpty)) - 1
 		}
 		styledBar = styledBar[:styledPos] + indicatorStyle.Render(indicator) + styledBar[styledPos+len(indicatorStyle.Render(indicator)):]
 	}
@@ -127,14 +131,15 @@ type TaskProgress struct {
 	HasError bool
 }
 
-// TaskList creates a list of tasks with progress
-func TaskList(tasks []TaskProgress, width int) string {
+// TaskList creates a list of tasks with progress. Active tasks are marked
+// with spinner, the current spinner frame, or a pointer when it is empty.
+func TaskList(tasks []TaskProgress, width int, spinner string) string {
 	if len(tasks) == 0 {
 		return ""
 	}
 
 	// Calculate the width of the task list
-	taskWidth := width - 4 // Subtract some padding
+	taskWidth := ClampWidth(width - 4) // Subtract some padding
 
 	// Create the task list
 	taskList := make([]string, 0, len(tasks))
@@ -154,16 +159,23 @@ func TaskList(tasks []TaskProgress, width int) string {
 		if task.IsDone {
 			name = nameStyle.Copy().
 				Foreground(SuccessColor).
-				Render("✓ " + task.Name)
+				Render(Symbols.Check + " " + task.Name)
 		} else if task.HasError {
 			name = nameStyle.Copy().
 				Foreground(ErrorColor).
-				Render("✗ " + task.Name)
+				Render(Symbols.Cross + " " + task.Name)
 		} else if task.IsActive {
-			name = nameStyle.Copy().
+			// The spinner is already styled, so it is kept out of nameStyle
+			marker := spinner
+			if marker == "" {
+				marker = Symbols.Pointer
+			}
+			marker += " "
+			name = marker + nameStyle.Copy().
+				Width(max(taskWidth/3-lipgloss.Width(marker), 0)).
 				Foreground(PrimaryColor).
 				Bold(true).
-				Render("▶ " + task.Name)
+				Render(task.Name)
 		} else {
 			name = nameStyle.Render("  " + task.Name)
 		}
@@ -2,15 +2,21 @@ package ui
 
 import (
 	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 // SearchBox creates a search box
 func SearchBox(query string, width int, focused bool) string {
+	return SearchBoxWithCompletion(query, "", width, focused)
+}
+
+// SearchBoxWithCompletion creates a search box that shows a dimmed completion after the query
+func SearchBoxWithCompletion(query, completion string, width int, focused bool) string {
 	// Create a box style for the search box
 	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
+		Border(Border()).
 		BorderForeground(AccentColor).
 		Padding(0, 1).
 		Width(width)
@@ -30,8 +36,41 @@ func SearchBox(query string, width int, focused bool) string {
 		cursor = "_"
 	}
 
+	// Render the completion suffix dimmed, only while typing
+	suggestion := ""
+	if focused && completion != "" {
+		suggestion = lipgloss.NewStyle().
+			Foreground(DimmedColor).
+			Render(completion)
+	}
+
 	// Render the search box
-	return boxStyle.Render(prefix + query + cursor)
+	return boxStyle.Render(prefix + query + cursor + suggestion)
+}
+
+// InputBox creates a labelled single line input box
+func InputBox(label, value string, width int, focused bool) string {
+	boxStyle := lipgloss.NewStyle().
+		Border(Border()).
+		BorderForeground(AccentColor).
+		Padding(0, 1).
+		Width(width)
+
+	if focused {
+		boxStyle = boxStyle.BorderForeground(PrimaryColor)
+	}
+
+	prefix := lipgloss.NewStyle().
+		Foreground(SecondaryColor).
+		Render(label)
+
+	// Create a cursor if focused
+	cursor := ""
+	if focused {
+		cursor = "_"
+	}
+
+	return boxStyle.Render(prefix + value + cursor)
 }
 
 // FilterItems filters items based on a search query
@@ -60,20 +99,20 @@ func HighlightMatch(text, query string) string {
 		return text
 	}
 
-	// Convert to lowercase for case-insensitive search
-	lowerText := strings.ToLower(text)
-	lowerQuery := strings.ToLower(query)
+	// Compare runes so multi-byte characters are never split
+	textRunes := []rune(text)
+	queryRunes := []rune(query)
 
-	// Find the index of the query in the text
-	index := strings.Index(lowerText, lowerQuery)
+	// Find the index of the query in the text, ignoring case
+	index := indexRunesFold(textRunes, queryRunes)
 	if index == -1 {
 		return text
 	}
 
 	// Split the text into three parts: before, match, and after
-	before := text[:index]
-	match := text[index : index+len(query)]
-	after := text[index+len(query):]
+	before := string(textRunes[:index])
+	match := string(textRunes[index : index+len(queryRunes)])
+	after := string(textRunes[index+len(queryRunes):])
 
 	// Highlight the match
 	highlightedMatch := lipgloss.NewStyle().
@@ -84,3 +123,27 @@ func HighlightMatch(text, query string) string {
 	// Combine the parts
 	return before + highlightedMatch + after
 }
+
+// HasPrefixFold reports whether text starts with prefix, ignoring case. It
+// compares runes like HighlightMatch, so multi-byte characters match whole.
+func HasPrefixFold(text, prefix []rune) bool {
+	return len(prefix) <= len(text) && indexRunesFold(text[:len(prefix)], prefix) == 0
+}
+
+// indexRunesFold returns the rune index of the first case-insensitive
+// occurrence of query in text, or -1 if there is none
+func indexRunesFold(text, query []rune) int {
+	for i := 0; i+len(query) <= len(text); i++ {
+		matched := true
+		for j, r := range query {
+			if unicode.ToLower(text[i+j]) != unicode.ToLower(r) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
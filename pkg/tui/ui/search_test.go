@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestHighlightMatchKeepsMultiByteCharacters(t *testing.T) {
+	// Render styles even though the tests don't run in a terminal
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	tests := []struct {
+		text  string
+		query string
+		match string
+	}{
+		{"Café", "fé", "fé"},
+		{"Ünïcode", "nïc", "nïc"},
+		{"ÜNÏCODE", "nïc", "NÏC"},
+		{"🦀 Rust Tools", "rust", "Rust"},
+		{"🦀🔥 Fire", "🔥", "🔥"},
+		{"日本語エディタ", "エディ", "エディ"},
+	}
+
+	for _, tt := range tests {
+		got := HighlightMatch(tt.text, tt.query)
+		if !utf8.ValidString(got) {
+			t.Errorf("HighlightMatch(%q, %q) = %q, not valid UTF-8", tt.text, tt.query, got)
+		}
+		if got == tt.text {
+			t.Errorf("HighlightMatch(%q, %q) didn't highlight anything", tt.text, tt.query)
+		}
+		if !strings.Contains(got, tt.match) {
+			t.Errorf("HighlightMatch(%q, %q) = %q, want the match %q unbroken", tt.text, tt.query, got, tt.match)
+		}
+		if plain := stripANSI(got); plain != tt.text {
+			t.Errorf("HighlightMatch(%q, %q) reads %q without styling, want the text unchanged", tt.text, tt.query, plain)
+		}
+	}
+}
+
+func TestHighlightMatchWithoutMatch(t *testing.T) {
+	if got := HighlightMatch("Café", "xyz"); got != "Café" {
+		t.Errorf("HighlightMatch() = %q, want the text unchanged", got)
+	}
+}
+
+// stripANSI removes the escape sequences styling adds
+func stripANSI(s string) string {
+	var b strings.Builder
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func TestHasPrefixFold(t *testing.T) {
+	tests := []struct {
+		text   string
+		prefix string
+		want   bool
+	}{
+		{"Ünïcode", "ünÏ", true},
+		{"\u212Aelvin", "ke", true}, // The three-byte Kelvin sign lowercases to a one-byte k
+		{"Firefox", "fire", true},
+		{"Firefox", "fox", false},
+		{"Fire", "Firefox", false},
+	}
+
+	for _, tt := range tests {
+		if got := HasPrefixFold([]rune(tt.text), []rune(tt.prefix)); got != tt.want {
+			t.Errorf("HasPrefixFold(%q, %q) = %v, want %v", tt.text, tt.prefix, got, tt.want)
+		}
+	}
+}
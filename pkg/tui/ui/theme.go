@@ -0,0 +1,92 @@
+package ui
+
+import "github.com/charmbracelet/lipgloss"
+
+// SymbolSet holds the markers drawn by the UI helpers
+type SymbolSet struct {
+	Pointer     string // Marks the active item
+	Check       string // Marks something done
+	Cross       string // Marks something failed
+	Warning     string
+	Info        string
+	Bullet      string
+	Changed     string // Marks an option that differs from its default
+	CheckboxOn  string
+	CheckboxOff string
+	BarFilled   string // Filled part of a progress bar
+	BarEmpty    string // Empty part of a progress bar
+}
+
+// Symbol sets
+var (
+	UnicodeSymbols = SymbolSet{
+		Pointer:     "▶",
+		Check:       "✓",
+		Cross:       "✗",
+		Warning:     "⚠",
+		Info:        "ℹ",
+		Bullet:      "•",
+		Changed:     "●",
+		CheckboxOn:  "[✓]",
+		CheckboxOff: "[ ]",
+		BarFilled:   "█",
+		BarEmpty:    "░",
+	}
+
+	ASCIISymbols = SymbolSet{
+		Pointer:     ">",
+		Check:       "[done]",
+		Cross:       "[fail]",
+		Warning:     "[!]",
+		Info:        "[i]",
+		Bullet:      "-",
+		Changed:     "*",
+		CheckboxOn:  "[x]",
+		CheckboxOff: "[ ]",
+		BarFilled:   "#",
+		BarEmpty:    ".",
+	}
+)
+
+// Symbols are the markers currently in use
+var Symbols = UnicodeSymbols
+
+// asciiBorder draws boxes with plain ASCII characters
+var asciiBorder = lipgloss.Border{
+	Top:         "-",
+	Bottom:      "-",
+	Left:        "|",
+	Right:       "|",
+	TopLeft:     "+",
+	TopRight:    "+",
+	BottomLeft:  "+",
+	BottomRight: "+",
+}
+
+// asciiMode is set by UseASCII
+var asciiMode bool
+
+// UseASCII switches to ASCII markers and borders and a black-and-white
+// palette, for basic terminals and users who can't tell the theme colors apart
+func UseASCII() {
+	asciiMode = true
+	Symbols = ASCIISymbols
+
+	PrimaryColor = lipgloss.Color("15")
+	SecondaryColor = lipgloss.Color("15")
+	SuccessColor = lipgloss.Color("15")
+	WarningColor = lipgloss.Color("15")
+	ErrorColor = lipgloss.Color("15")
+	TextColor = lipgloss.Color("15")
+	DimmedColor = lipgloss.Color("7")
+	AccentColor = lipgloss.Color("15")
+	BackgroundColor = lipgloss.Color("0")
+}
+
+// Border returns the border used for boxes
+func Border() lipgloss.Border {
+	if asciiMode {
+		return asciiBorder
+	}
+	return lipgloss.RoundedBorder()
+}
@@ -1,28 +1,62 @@
 package tui
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// ForceQuitWindow is how close together two Ctrl+C presses must be to quit
+// the program, whatever the installer is in the middle of
+const ForceQuitWindow = 2 * time.Second
+
 // Update updates the model based on the message
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Someone is at the keyboard, so the watchdog doesn't need to step in
+		m.lastActivity = time.Now()
+
+		// Quit on a second Ctrl+C, even if quit is bound to another key, a
+		// modal is open or a parent model ignores the first one
+		if msg.Type == tea.KeyCtrlC {
+			if time.Since(m.lastInterrupt) < ForceQuitWindow {
+				return m, tea.Quit
+			}
+			m.lastInterrupt = time.Now()
+		}
+
+		// Package names are typed as is, only Ctrl+C still quits
+		if m.extraFocused && msg.Type != tea.KeyCtrlC {
+			return m.handleExtraInput(msg)
+		}
+		if m.logSearchFocused && msg.Type != tea.KeyCtrlC {
+			return m.handleLogSearchInput(msg)
+		}
+		if m.confirmingInstall && !key.Matches(msg, m.keyMap.Quit) {
+			return m.handleInstallConfirmation(msg)
+		}
+
 		// Global key handlers
 		switch {
 		case key.Matches(msg, m.keyMap.Quit):
-			return m, tea.Quit
+			return m, m.quit()
 
 		case key.Matches(msg, m.keyMap.Help):
 			m.showHelp = !m.showHelp
 			return m, nil
 
-		case key.Matches(msg, m.keyMap.Search):
+		case key.Matches(msg, m.keyMap.Search) && m.router.CurrentPage() != LogPage:
 			// Toggle search focus
 			m.searchFocused = !m.searchFocused
 			if !m.searchFocused {
@@ -60,11 +94,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleSearchInput(msg)
 		}
 
+		// Hide or show the notification stack on every page
+		if key.Matches(msg, m.keyMap.Notifications) {
+			m.showNotifications = !m.showNotifications
+			return m, nil
+		}
+
 		// Get the current route
 		currentPage := m.router.CurrentPage()
 		if route, ok := m.router.GetRoute(currentPage); ok {
 			// Use the route's updater
-			return route.Updater(msg)
+			return route.Updater(m, msg)
 		}
 
 	case tea.WindowSizeMsg:
@@ -73,8 +113,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.help.Width = msg.Width
 
 		// Update message renderer dimensions
-		m.messageRenderer.SetWidth(m.width - 10) // Subtract some padding
-		m.messageRenderer.SetHeight(15)          // Fixed height for messages
+		m.messageRenderer.SetWidth(ui.ClampWidth(m.width - 10)) // Subtract some padding
+		m.messageRenderer.SetHeight(15)                         // Fixed height for messages
 
 		// If we're on the welcome page and just got window size, navigate to AUR helper page
 		if m.router.CurrentPage() == WelcomePage && m.animating {
@@ -92,6 +132,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case InstallProgressMsg:
 		return m.handleInstallProgress(msg)
 
+	case watchdogTickMsg:
+		return m.handleWatchdogTick()
+
 	case PageTransitionMsg:
 		return m.handlePageTransition(msg)
 
@@ -109,6 +152,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case indeterminateProgressTickMsg:
 		return m.handleIndeterminateProgressTick()
+
+	case PreflightMsg:
+		return m.handlePreflight(msg)
+
+	case preparedMsg:
+		return m.handlePrepared(msg)
+
+	case AvailabilityMsg:
+		return m.handleAvailability(msg)
+
+	case hyprlandVersionMsg:
+		return m.handleHyprlandVersion(msg)
+
+	case connectivityMsg:
+		return m.handleConnectivity(msg)
+
+	case pagerClosedMsg:
+		return m.handlePagerClosed(msg)
+
+	case shellClosedMsg:
+		return m.handleShellClosed(msg)
+
+	case windowSizeTimeoutMsg:
+		return m.handleWindowSizeTimeout()
 	}
 
 	// Return any batched commands
@@ -215,8 +282,34 @@ func (m Model) handleConflictInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // updateWelcomePage updates the welcome page
 func (m Model) updateWelcomePage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Stop before making any changes when they can't be written
+	if m.homeError != nil {
+		if msg.Type == tea.KeyEnter || msg.Type == tea.KeySpace {
+			return m, m.AddErrorNotification("Home Directory Not Writable", "Fix the permissions or mount of the home directory and restart the installer")
+		}
+		return m, nil
+	}
+
+	// Require explicit confirmation before continuing on an unsupported system
+	if m.unsupportedDistro && !m.distroConfirmed {
+		switch msg.String() {
+		case "y", "Y":
+			m.distroConfirmed = true
+			return m, m.AddWarningNotification("Unsupported System", "Continuing anyway, some steps may fail")
+		case "enter", " ":
+			return m, m.AddWarningNotification("Unsupported System", "Press y to continue on this system anyway")
+		}
+		return m, nil
+	}
+
 	switch msg.Type {
 	case tea.KeyEnter, tea.KeySpace:
+		// Continue the interrupted installation straight away with --resume
+		if m.resume != nil {
+			m.resumeFromCheckpoint()
+			return m.router.Navigate(InstallationPage, m)
+		}
+
 		// Use the router to navigate to the AUR helper page
 		return m.router.Navigate(AURHelperPage, m)
 	}
@@ -229,13 +322,22 @@ func (m Model) updateAURHelperPage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keyMap.Up):
 		m.aurHelperIndex = max(0, m.aurHelperIndex-1)
 	case key.Matches(msg, m.keyMap.Down):
-		m.aurHelperIndex = min(len(m.aurHelperOptions)-1, m.aurHelperIndex+1)
+		m.aurHelperIndex = max(0, min(len(m.aurHelperOptions)-1, m.aurHelperIndex+1))
 	case key.Matches(msg, m.keyMap.Enter):
+		// Nothing to select without AUR helper options
+		if m.aurHelperIndex < 0 || m.aurHelperIndex >= len(m.aurHelperOptions) {
+			return m, m.AddErrorNotification("No AUR Helper", "No AUR helpers are available")
+		}
+
 		// Set the AUR helper
-		m.aurHelper = aur.NewHelper(m.aurHelperOptions[m.aurHelperIndex])
+		m.aurHelper = m.newAURHelper(m.aurHelperOptions[m.aurHelperIndex])
 
-		// Initialize selected options with defaults
+		// Initialize selected options with defaults, keeping the selection of
+		// categories already visited when coming back to change the AUR helper
 		for _, category := range m.categories {
+			if _, ok := m.selectedOptions[category.Name]; ok {
+				continue
+			}
 			for _, option := range category.Options {
 				if option.Default {
 					if _, ok := m.selectedOptions[category.Name]; !ok {
@@ -255,8 +357,41 @@ func (m Model) updateAURHelperPage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// newAURHelper returns the AUR helper name configured with the install options
+func (m Model) newAURHelper(name string) PackageManager {
+	if m.newPackageManager != nil {
+		return m.newPackageManager(name)
+	}
+
+	helper := aur.NewHelper(name)
+	helper.SetTimeout(m.options.Timeout)
+	helper.SetReinstall(m.options.Reinstall)
+	helper.SetBuildOptions(aur.BuildOptions{
+		MakeJobs: m.options.MakeJobs,
+		Nice:     m.options.Nice,
+		NoIONice: m.options.NoIONice,
+	})
+	return helper
+}
+
 // updatePackageCategoriesPage updates the package categories page
 func (m Model) updatePackageCategoriesPage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Without categories there is nothing to select, only allow going back
+	if len(m.categories) == 0 {
+		if key.Matches(msg, m.keyMap.Back) {
+			return m.router.Back(m)
+		}
+		return m, nil
+	}
+
+	// Keep the category index in range in case the categories changed
+	m.categoryIndex = max(0, min(m.categoryIndex, len(m.categories)-1))
+
+	// Navigate the options matching the tag filter instead of the current category
+	if m.tagFilter != "" && (key.Matches(msg, m.keyMap.Up) || key.Matches(msg, m.keyMap.Down) || key.Matches(msg, m.keyMap.Enter)) {
+		return m.updateTagFilter(msg)
+	}
+
 	switch {
 	case key.Matches(msg, m.keyMap.Tab):
 		// Toggle focus between categories and options
@@ -288,43 +423,150 @@ func (m Model) updatePackageCategoriesPage(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			m.optionIndex = 0
 		} else {
 			// Toggle option selection
-			category := m.categories[m.categoryIndex]
-			option := category.Options[m.optionIndex]
-
-			// Initialize the map entry if it doesn't exist
-			if _, ok := m.selectedOptions[category.Name]; !ok {
-				m.selectedOptions[category.Name] = []string{}
-			}
-
-			// Check if the option is already selected
-			isSelected := false
-			for i, selectedOption := range m.selectedOptions[category.Name] {
-				if selectedOption == option.Name {
-					// Remove the option
-					m.selectedOptions[category.Name] = append(
-						m.selectedOptions[category.Name][:i],
-						m.selectedOptions[category.Name][i+1:]...,
-					)
-					isSelected = true
-					break
-				}
-			}
-
-			// If not selected, add it
-			if !isSelected {
-				m.selectedOptions[category.Name] = append(m.selectedOptions[category.Name], option.Name)
-			}
+			return m, m.toggleOption(m.categoryIndex, m.optionIndex)
 		}
 	case key.Matches(msg, m.keyMap.Back):
 		// Use the router to navigate back
 		return m.router.Back(m)
+	case key.Matches(msg, m.keyMap.Reset):
+		// Reset the current category to its default option
+		category := m.categories[m.categoryIndex]
+		m.resetToDefaults(category.Name)
+		return m, m.AddInfoNotification("Selection Reset", fmt.Sprintf("%s reset to the default selection", category.Name))
+	case key.Matches(msg, m.keyMap.ResetAll):
+		// Reset every category to its default option
+		for _, category := range m.categories {
+			m.resetToDefaults(category.Name)
+		}
+		return m, m.AddInfoNotification("Selection Reset", "All categories reset to the default selection")
+	case key.Matches(msg, m.keyMap.Advanced):
+		// Toggle the advanced base package category
+		return m, m.toggleAdvancedMode()
+	case key.Matches(msg, m.keyMap.Tag):
+		// Show only the options with the next tag
+		return m, m.cycleTagFilter()
+	case key.Matches(msg, m.keyMap.Extra):
+		// Edit the extra packages, starting from the current list
+		m.extraFocused = true
+		m.extraInput = strings.Join(m.extraPackages, ", ")
 	case key.Matches(msg, m.keyMap.Right):
-		// Use the router to navigate to the installation page
-		return m.router.Navigate(InstallationPage, m)
+		// Confirm the number of packages, then go to the installation page
+		return m.confirmInstall()
 	}
 	return m, nil
 }
 
+// toggleOption selects or deselects an option of a category
+func (m *Model) toggleOption(categoryIndex, optionIndex int) tea.Cmd {
+	category := m.categories[categoryIndex]
+	if optionIndex < 0 || optionIndex >= len(category.Options) {
+		return nil
+	}
+	option := category.Options[optionIndex]
+
+	// Experimental options are only selected when toggled twice in a row
+	pending := m.pendingOption
+	m.pendingOption = ""
+	if option.Experimental() && !slices.Contains(m.selectedOptions[category.Name], option.Name) {
+		if name := category.Name + "/" + option.Name; pending != name {
+			m.pendingOption = name
+			return m.AddWarningNotification("Experimental Option", fmt.Sprintf("%s tracks upstream and may break, press Enter again to select it", option.Name))
+		}
+	}
+
+	// Initialize the map entry if it doesn't exist
+	if _, ok := m.selectedOptions[category.Name]; !ok {
+		m.selectedOptions[category.Name] = []string{}
+	}
+
+	// Check if the option is already selected
+	isSelected := false
+	for i, selectedOption := range m.selectedOptions[category.Name] {
+		if selectedOption == option.Name {
+			// Remove the option
+			m.selectedOptions[category.Name] = append(
+				m.selectedOptions[category.Name][:i],
+				m.selectedOptions[category.Name][i+1:]...,
+			)
+			isSelected = true
+			break
+		}
+	}
+
+	// If not selected, add it
+	if !isSelected {
+		m.selectedOptions[category.Name] = append(m.selectedOptions[category.Name], option.Name)
+	}
+
+	// Track deselected base packages in advanced mode
+	if category.Name == config.BasePackagesCategoryName {
+		m.deselectedBasePackages[option.Name] = isSelected
+	}
+	return nil
+}
+
+// resetToDefaults replaces the selection of a category with its default options
+func (m *Model) resetToDefaults(categoryName string) {
+	for _, category := range m.categories {
+		if category.Name != categoryName {
+			continue
+		}
+
+		defaults := []string{}
+		for _, option := range category.Options {
+			if option.Default {
+				defaults = append(defaults, option.Name)
+			}
+		}
+		m.selectedOptions[category.Name] = defaults
+
+		// Every base package is selected by default
+		if category.Name == config.BasePackagesCategoryName {
+			m.deselectedBasePackages = make(map[string]bool)
+		}
+		return
+	}
+}
+
+// toggleAdvancedMode shows or hides the base packages as an editable category
+func (m *Model) toggleAdvancedMode() tea.Cmd {
+	m.advancedMode = !m.advancedMode
+
+	if m.advancedMode {
+		// Show the base packages, keeping any previous deselections
+		category := config.BasePackagesCategory()
+		selected := []string{}
+		for _, option := range category.Options {
+			if !m.deselectedBasePackages[option.Name] {
+				selected = append(selected, option.Name)
+			}
+		}
+		m.categories = append(m.categories, category)
+		m.selectedOptions[category.Name] = selected
+
+		return m.AddWarningNotification("Advanced Mode", fmt.Sprintf("Base packages can now be deselected, HyprLuna may not work without them. Filter by the %s tag with t to find the ones known to fail", config.FragileTag))
+	}
+
+	// Hide the base packages and restore the full base package set
+	categories := make([]config.PackageCategory, 0, len(m.categories))
+	for _, category := range m.categories {
+		if category.Name != config.BasePackagesCategoryName {
+			categories = append(categories, category)
+		}
+	}
+	m.categories = categories
+	delete(m.selectedOptions, config.BasePackagesCategoryName)
+	m.deselectedBasePackages = make(map[string]bool)
+
+	// Keep the category index within bounds
+	if m.categoryIndex >= len(m.categories) {
+		m.categoryIndex = len(m.categories) - 1
+		m.optionIndex = -1
+	}
+
+	return m.AddInfoNotification("Advanced Mode", "All base packages will be installed")
+}
+
 // updateInstallationPage updates the installation page
 func (m Model) updateInstallationPage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle dotfiles confirmation
@@ -363,19 +605,123 @@ func (m Model) updateInstallationPage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	// No key handlers for other installation phases
+	// Handle overwrite confirmation
+	if m.installPhase == "overwrite_confirmation" {
+		switch msg.Type {
+		case tea.KeyUp, tea.KeyDown:
+			// Toggle between Yes and No
+			m.overwriteConfirmation = !m.overwriteConfirmation
+			return m, nil
+
+		case tea.KeyEnter, tea.KeySpace:
+			// Confirm selection and continue installation
+			return m, m.continueInstallation()
+
+		case tea.KeyEsc:
+			// Cancel installation
+			return m.router.Navigate(PackageCategoriesPage, m)
+		}
+	}
+
+	// Handle wallpaper selection
+	if m.installPhase == "wallpaper_selection" {
+		switch msg.Type {
+		case tea.KeyUp:
+			m.moveWallpaperSelection(-1)
+			return m, nil
+
+		case tea.KeyDown:
+			m.moveWallpaperSelection(1)
+			return m, nil
+
+		case tea.KeyEnter, tea.KeySpace:
+			// Confirm selection and continue installation
+			return m, m.continueInstallation()
+		}
+	}
+
+	// Handle services confirmation
+	if m.installPhase == "services_confirmation" {
+		switch msg.Type {
+		case tea.KeyUp, tea.KeyDown:
+			// Toggle between Yes and No
+			m.servicesConfirmation = !m.servicesConfirmation
+			return m, nil
+
+		case tea.KeyEnter, tea.KeySpace:
+			// Confirm selection and continue installation
+			return m, m.continueInstallation()
+		}
+	}
+
+	// Copy the command output to the clipboard
+	if key.Matches(msg, m.keyMap.Copy) {
+		return m, m.copyOutputToClipboard()
+	}
+
+	// Show more or less of the command output
+	if key.Matches(msg, m.keyMap.Verbosity) {
+		return m, m.cycleVerbosity()
+	}
+
+	// Retry a step that failed because of the network
+	if m.retryStep != "" && key.Matches(msg, m.keyMap.Retry) {
+		return m, m.retryInstallation()
+	}
+
+	// Write a diagnostics report once the installation has failed
+	if m.errorMessage != "" && key.Matches(msg, m.keyMap.Report) {
+		return m, m.writeDiagnosticsCmd()
+	}
+
+	// Open a shell to fix the failure by hand
+	if m.errorMessage != "" && key.Matches(msg, m.keyMap.Shell) {
+		return m, m.openShell()
+	}
+
 	return m, nil
 }
 
 // updateCompletePage updates the complete page
 func (m Model) updateCompletePage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	retryable := len(m.failedPackages) > 0
+
 	switch {
 	case key.Matches(msg, m.keyMap.Enter):
-		return m, tea.Quit
+		if retryable && m.completeRetry {
+			return m.retryFailedPage()
+		}
+		return m, m.quit()
+	case retryable && key.Matches(msg, m.keyMap.Retry):
+		return m.retryFailedPage()
+	case key.Matches(msg, m.keyMap.Copy):
+		return m, m.copyOutputToClipboard()
+	case key.Matches(msg, m.keyMap.CopyCommand):
+		return m, m.copyCommandToClipboard(m.queuedPackages)
+	case key.Matches(msg, m.keyMap.Report):
+		return m, m.writeDiagnosticsCmd()
+	case key.Matches(msg, m.keyMap.View):
+		return m.openLogViewer()
+	case key.Matches(msg, m.keyMap.Log):
+		return m, m.openLogInPager()
+	case retryable && key.Matches(msg, m.keyMap.Left):
+		m.completeRetry = true
+	case retryable && key.Matches(msg, m.keyMap.Right):
+		m.completeRetry = false
 	}
 	return m, nil
 }
 
+// retryFailedPage goes back to the installation page to install the
+// packages that failed again
+func (m Model) retryFailedPage() (tea.Model, tea.Cmd) {
+	m.completeRetry = false
+	m.page = InstallationPage
+	cmd := m.retryFailedPackages()
+	next, navCmd := m.router.Navigate(InstallationPage, m)
+	return next, tea.Batch(navCmd, cmd)
+}
+
 // max returns the maximum of two integers
 func max(a, b int) int {
 	if a > b {
@@ -0,0 +1,313 @@
+package tui
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copySelection returns a deep copy of a selection map
+func copySelection(selection map[string][]string) map[string][]string {
+	copied := make(map[string][]string, len(selection))
+	for name, options := range selection {
+		copied[name] = slices.Clone(options)
+	}
+	return copied
+}
+
+// press sends a key to m and returns the updated model
+func press(t *testing.T, m Model, msg tea.KeyMsg) Model {
+	t.Helper()
+	next, _ := m.Update(msg)
+	return next.(Model)
+}
+
+func TestSelectionKeptWhenGoingBack(t *testing.T) {
+	m := NewModelWithOptions(config.DefaultOptions())
+	next, _ := m.router.Navigate(AURHelperPage, m)
+	m = next.(Model)
+
+	// Pick an AUR helper, then change the default selection
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if page := m.router.CurrentPage(); page != PackageCategoriesPage {
+		t.Fatalf("CurrentPage() = %d, want %d", page, PackageCategoriesPage)
+	}
+	m.toggleOption(0, 0)
+	m.toggleOption(0, 1)
+	want := copySelection(m.selectedOptions)
+
+	// Go back to the AUR helper page and pick it again
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if page := m.router.CurrentPage(); page != AURHelperPage {
+		t.Fatalf("CurrentPage() = %d, want %d", page, AURHelperPage)
+	}
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if !maps.EqualFunc(m.selectedOptions, want, slices.Equal[[]string]) {
+		t.Errorf("selection after picking the AUR helper again = %v, want %v", m.selectedOptions, want)
+	}
+
+	// Go on to the installation and come back
+	next, _ = m.router.Navigate(InstallationPage, m)
+	m = next.(Model)
+	next, _ = m.router.Back(m)
+	m = next.(Model)
+
+	if page := m.router.CurrentPage(); page != PackageCategoriesPage {
+		t.Fatalf("CurrentPage() = %d, want %d", page, PackageCategoriesPage)
+	}
+	if !maps.EqualFunc(m.selectedOptions, want, slices.Equal[[]string]) {
+		t.Errorf("selection after returning from the installation = %v, want %v", m.selectedOptions, want)
+	}
+}
+
+func TestInstallConfirmation(t *testing.T) {
+	m := NewModelWithOptions(config.DefaultOptions())
+	next, _ := m.router.Navigate(AURHelperPage, m)
+	m = press(t, next.(Model), tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Going on asks for confirmation first
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	if !m.confirmingInstall {
+		t.Fatal("confirmingInstall = false after pressing Right, want true")
+	}
+	if m.confirmPackageCount == 0 {
+		t.Error("confirmPackageCount = 0, want the number of selected packages")
+	}
+
+	// Esc dismisses the confirmation without leaving the page
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.confirmingInstall {
+		t.Error("confirmingInstall = true after pressing Esc, want false")
+	}
+	if page := m.router.CurrentPage(); page != PackageCategoriesPage {
+		t.Fatalf("CurrentPage() = %d, want %d", page, PackageCategoriesPage)
+	}
+
+	// Enter confirms and starts the installation
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if page := m.router.CurrentPage(); page != InstallationPage {
+		t.Errorf("CurrentPage() = %d, want %d", page, InstallationPage)
+	}
+}
+
+func TestBackToWelcomeResetsAURHelper(t *testing.T) {
+	m := NewModelWithOptions(config.DefaultOptions())
+
+	// Pick the first AUR helper and change the default selection
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.aurHelper == nil {
+		t.Fatal("aurHelper = nil after picking one")
+	}
+	m.toggleOption(0, 1)
+	want := copySelection(m.selectedOptions)
+
+	// Go back to the welcome page
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if page := m.router.CurrentPage(); page != WelcomePage {
+		t.Fatalf("CurrentPage() = %d, want %d", page, WelcomePage)
+	}
+	if m.aurHelper != nil {
+		t.Errorf("aurHelper = %s after going back to the welcome page, want nil", m.aurHelper.String())
+	}
+
+	// Come forward again and pick another AUR helper
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if page := m.router.CurrentPage(); page != PackageCategoriesPage {
+		t.Fatalf("CurrentPage() = %d, want %d", page, PackageCategoriesPage)
+	}
+	if m.aurHelper == nil || m.aurHelper.String() != m.aurHelperOptions[1] {
+		t.Errorf("aurHelper = %v, want %s", m.aurHelper, m.aurHelperOptions[1])
+	}
+	if !maps.EqualFunc(m.selectedOptions, want, slices.Equal[[]string]) {
+		t.Errorf("selection after coming forward again = %v, want %v", m.selectedOptions, want)
+	}
+}
+
+func TestPasswordKeepsProgress(t *testing.T) {
+	m := NewModelWithOptions(config.DefaultOptions())
+	m.aurHelper = aur.NewHelper(aur.PacmanOnly)
+	next, _ := m.router.Navigate(InstallationPage, m)
+	next, _ = next.(Model).handlePrepared(preparedMsg{Packages: []string{"pkg-a", "pkg-b"}})
+	m = next.(Model)
+	if !m.awaitingPassword {
+		t.Fatal("awaitingPassword = false once the packages are prepared, want true")
+	}
+	progress, total := m.installProgress, m.totalSteps
+
+	// Typing the password doesn't change the progress
+	for _, r := range "secret" {
+		m = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if m.installProgress != progress || m.totalSteps != total {
+		t.Fatalf("progress while typing the password = %d/%d, want %d/%d", m.installProgress, m.totalSteps, progress, total)
+	}
+
+	// Submitting it starts the AUR helper step, exactly one step further
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.awaitingPassword {
+		t.Fatal("awaitingPassword = true after submitting the password, want false")
+	}
+	if m.installProgress != progress+1 || m.totalSteps != total {
+		t.Fatalf("progress after the password = %d/%d, want %d/%d", m.installProgress, m.totalSteps, progress+1, total)
+	}
+
+	// The AUR helper step reports its progress and the first package follows,
+	// progress messages are handled by a pointer to the model
+	next, _ = m.Update(cmd())
+	m = *next.(*Model)
+	if m.installProgress != progress+2 || m.totalSteps != total {
+		t.Errorf("progress after the AUR helper = %d/%d, want %d/%d", m.installProgress, m.totalSteps, progress+2, total)
+	}
+	if !slices.Equal(m.packagesToInstall, []string{"pkg-b"}) {
+		t.Errorf("packagesToInstall = %v, want [pkg-b]", m.packagesToInstall)
+	}
+}
+
+func TestRetryFailedPackages(t *testing.T) {
+	m := NewModelWithOptions(config.DefaultOptions())
+	m.aurHelper = aur.NewHelper(aur.PacmanOnly)
+	m.installedPackages = []string{"pkg-a"}
+	m.failedPackages = []string{"pkg-b", "pkg-c"}
+	next, _ := m.router.Navigate(CompletePage, m)
+	m = next.(Model)
+
+	// Retrying installs only the failed packages, which are collected anew
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if page := m.router.CurrentPage(); page != InstallationPage {
+		t.Fatalf("CurrentPage() = %d, want %d", page, InstallationPage)
+	}
+	if len(m.failedPackages) != 0 {
+		t.Errorf("failedPackages = %v, want none until the retry fails again", m.failedPackages)
+	}
+	if !slices.Equal(m.packagesToInstall, []string{"pkg-c"}) {
+		t.Errorf("packagesToInstall = %v, want [pkg-c] once pkg-b is being installed", m.packagesToInstall)
+	}
+	if m.installProgress != 1 || m.totalSteps != 2 {
+		t.Errorf("progress = %d/%d, want 1/2", m.installProgress, m.totalSteps)
+	}
+
+	// Once the last package was handled, the complete page follows instead
+	// of the dotfiles confirmation
+	m.packagesToInstall = nil
+	msg := m.finishPackages()()
+	if progress, ok := msg.(InstallProgressMsg); !ok || !progress.IsComplete {
+		t.Errorf("finishPackages() after the retry = %#v, want the complete message", msg)
+	}
+	if m.retryingFailed {
+		t.Error("retryingFailed = true once the retry finished, want false")
+	}
+}
+
+func TestDoubleCtrlCQuits(t *testing.T) {
+	// With quit bound to another key, the first press doesn't quit
+	m := NewModelWithOptions(config.DefaultOptions())
+	m.keyMap.Quit = key.NewBinding(key.WithKeys("q"))
+
+	ctrlC := tea.KeyMsg{Type: tea.KeyCtrlC}
+	next, cmd := m.Update(ctrlC)
+	if cmd != nil {
+		if _, ok := cmd().(tea.QuitMsg); ok {
+			t.Fatal("the first Ctrl+C quit the program")
+		}
+	}
+
+	_, cmd = next.Update(ctrlC)
+	if cmd == nil {
+		t.Fatal("the second Ctrl+C returned no command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("the second Ctrl+C didn't quit the program")
+	}
+}
+
+func TestZeroOptionsModelAddsMessages(t *testing.T) {
+	m := NewEmbeddedModel(config.Options{})
+	for i := 0; i < 5; i++ {
+		m.AddInfoMessage("message", "test")
+	}
+
+	if got := len(m.messageQueue.Get()); got != 5 {
+		t.Errorf("queue holds %d messages, want 5", got)
+	}
+}
+
+// downloadingPackageManager reports a download that advances on every poll
+type downloadingPackageManager struct {
+	fakePackageManager
+	downloaded uint64
+}
+
+func (d *downloadingPackageManager) DownloadProgress() (uint64, uint64) {
+	d.downloaded += 1024
+	return d.downloaded, 1 << 30
+}
+
+func TestWatchdogSparesStepReportingProgress(t *testing.T) {
+	opts := config.DefaultOptions()
+	opts.IdleTimeout = 50 * time.Millisecond
+	m := NewModelWithOptions(opts)
+	next, _ := m.router.Navigate(InstallationPage, m)
+	m = next.(Model)
+	m.lastActivity = time.Now()
+
+	// The download keeps going for longer than the idle timeout
+	downloads := &downloadingPackageManager{}
+	m.aurHelper = downloads
+	for i := 0; i < 5; i++ {
+		time.Sleep(opts.IdleTimeout / 2)
+
+		next, _ = m.handleIndeterminateProgressTick()
+		m = next.(Model)
+		next, _ = m.handleWatchdogTick()
+		m = next.(Model)
+		if m.StallError() != nil {
+			t.Fatalf("the watchdog aborted a step reporting progress: %v", m.StallError())
+		}
+	}
+
+	// Without progress, the step stalls
+	m.aurHelper = nil
+	time.Sleep(2 * opts.IdleTimeout)
+	next, _ = m.handleIndeterminateProgressTick()
+	m = next.(Model)
+	next, _ = m.handleWatchdogTick()
+	m = next.(Model)
+	if m.StallError() == nil {
+		t.Error("the watchdog didn't abort a step without progress")
+	}
+}
+
+func TestSearchCompletionCutsRunes(t *testing.T) {
+	tests := []struct {
+		option string
+		query  string
+		want   string
+	}{
+		{"Ünïcode", "ünÏ", "code"},
+		{"\u212Aelvin", "k", "elvin"}, // The three-byte Kelvin sign lowercases to a one-byte k
+		{"Firefox", "FIRE", "fox"},
+		{"Firefox", "Firefox", ""},
+	}
+
+	for _, tt := range tests {
+		m := NewModelWithOptions(config.DefaultOptions())
+		m.searchQuery = tt.query
+		m.filteredOptions = []string{tt.option}
+		if got := m.searchCompletion(); got != tt.want {
+			t.Errorf("completion of %q for %q = %q, want %q", tt.query, tt.option, got, tt.want)
+		}
+	}
+}
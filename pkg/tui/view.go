@@ -2,9 +2,15 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
 	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/utils"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -16,8 +22,8 @@ func (m Model) View() string {
 			Foreground(ui.TextColor).
 			Align(lipgloss.Center).
 			AlignVertical(lipgloss.Center).
-			Width(80). // Default width
-			Height(24) // Default height
+			Width(defaultWidth).
+			Height(defaultHeight)
 
 		spinnerText := ui.Spinner(m.spinner.View(), "Initializing...")
 		return loadingStyle.Render(spinnerText)
@@ -63,22 +69,22 @@ func (m Model) View() string {
 			Align(lipgloss.Center).
 			AlignVertical(lipgloss.Center).
 			Width(m.width).
-			Height(m.height)
+			Height(m.screenHeight())
 
-		return errorStyle.Render("Error: Page not found - " + string(currentPage))
+		return errorStyle.Render(fmt.Sprintf("Error: Page not found - %d", currentPage))
 	}
 
 	// Render the current page using the route's renderer
-	content := route.Renderer()
+	content := route.Renderer(m)
 
 	// If help is shown, render help as a dropdown below the content
 	if m.showHelp {
 		// Create a help box
 		helpBoxStyle := lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
+			Border(ui.Border()).
 			BorderForeground(ui.AccentColor).
 			Padding(1).
-			Width(m.width-4). // Subtract some padding
+			Width(ui.ClampWidth(m.width-4)). // Subtract some padding
 			Margin(1, 0, 0, 0)
 
 		// Render help content
@@ -89,17 +95,22 @@ func (m Model) View() string {
 		return lipgloss.JoinVertical(lipgloss.Left, content, helpBox)
 	}
 
-	// Add a footer with basic instructions
+	// Add a footer with key hints
 	footerStyle := lipgloss.NewStyle().
 		Foreground(ui.DimmedColor).
 		Align(lipgloss.Right).
 		Width(m.width).
 		Margin(1, 0, 0, 0)
 
-	footer := footerStyle.Render("Press ? for help")
+	// Show the keys relevant to the current page, followed by the help key
+	hints := append(append([]string{}, route.Hints...), hint("help", m.keyMap.Help))
+	footer := footerStyle.Render(strings.Join(hints, " · "))
 
-	// Render notifications if there are any
-	notifications := m.renderNotifications()
+	// Render notifications if there are any, unless they were hidden
+	notifications := ""
+	if m.showNotifications {
+		notifications = m.renderNotifications()
+	}
 	if notifications != "" {
 		// Position notifications at the top right
 		notificationsStyle := lipgloss.NewStyle().
@@ -140,8 +151,8 @@ func (m Model) centerHorizontally(content string) string {
 func (m Model) renderPasswordPrompt() string {
 	// Use our common page container style
 	pageStyle := lipgloss.NewStyle().
-		Width(m.width).   // Use full terminal width
-		Height(m.height). // Use full terminal height
+		Width(m.width).           // Use full terminal width
+		Height(m.screenHeight()). // Use full terminal height
 		Align(lipgloss.Center).
 		AlignVertical(lipgloss.Center)
 
@@ -168,23 +179,16 @@ func (m Model) renderPasswordPrompt() string {
 		passwordDisplay = strings.Repeat("*", len(m.passwordInput))
 	}
 
-	// Adjust box width based on terminal width
-	boxWidth := min(m.width-10, 60)
-
-	// Create a box for the password field
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ui.AccentColor).
-		Padding(1, 2).
-		Width(boxWidth).
-		Align(lipgloss.Center)
+	// Create a box for the password field, adjusted to the terminal width
+	boxStyle, _ := m.pageBox(m.pulseColor(), 10, 60)
+	boxStyle = boxStyle.Align(lipgloss.Center)
 
 	passwordField := boxStyle.Render(passwordDisplay)
 
 	// Render instructions
 	instructions := lipgloss.NewStyle().
 		Foreground(ui.TextColor).
-		Render("Press Enter to submit, Esc to cancel, Tab to toggle visibility")
+		Render(m.label("Press Enter to submit, Esc to cancel, Tab to toggle visibility", "Enter submit, Esc cancel, Tab show"))
 
 	// Combine the content
 	content := lipgloss.JoinVertical(
@@ -205,8 +209,8 @@ func (m Model) renderPasswordPrompt() string {
 func (m Model) renderConflictResolution() string {
 	// Use our common page container style
 	pageStyle := lipgloss.NewStyle().
-		Width(m.width).   // Use full terminal width
-		Height(m.height). // Use full terminal height
+		Width(m.width).           // Use full terminal width
+		Height(m.screenHeight()). // Use full terminal height
 		Align(lipgloss.Center).
 		AlignVertical(lipgloss.Center)
 
@@ -228,16 +232,9 @@ func (m Model) renderConflictResolution() string {
 
 	subtitle := subtitleStyle.Render("Please select how to resolve this conflict")
 
-	// Adjust box width based on terminal width
-	boxWidth := min(m.width-10, 70)
-
-	// Create a box for the conflict message
-	messageBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ui.ErrorColor).
-		Padding(1, 2).
-		Width(boxWidth).
-		Align(lipgloss.Left)
+	// Create a box for the conflict message, adjusted to the terminal width
+	messageBox, boxWidth := m.pageBox(ui.ErrorColor, 10, 70)
+	messageBox = messageBox.Align(lipgloss.Left)
 
 	// Format the conflict message with error styling
 	formattedMessage := lipgloss.NewStyle().
@@ -263,7 +260,7 @@ func (m Model) renderConflictResolution() string {
 	for _, option := range options {
 		// Create option with name and description
 		optionStyle := lipgloss.NewStyle().
-			Width(boxWidth - 4).
+			Width(ui.ClampWidth(boxWidth - 4)).
 			Align(lipgloss.Left)
 
 		// Format the option name
@@ -285,16 +282,20 @@ func (m Model) renderConflictResolution() string {
 		// Combine name and description
 		var nameText string
 		if option.selected {
-			nameText = "▶ " + option.name
+			nameText = ui.Symbols.Pointer + " " + option.name
 		} else {
 			nameText = "  " + option.name
 		}
 
-		optionText := lipgloss.JoinVertical(
-			lipgloss.Left,
-			nameStyle.Render(nameText),
-			descStyle.Render("   "+option.description),
-		)
+		// The compact layout only lists the option names
+		optionText := nameStyle.Render(nameText)
+		if !m.compact() {
+			optionText = lipgloss.JoinVertical(
+				lipgloss.Left,
+				optionText,
+				descStyle.Render("   "+option.description),
+			)
+		}
 
 		formattedOptions = append(formattedOptions, optionStyle.Render(optionText))
 	}
@@ -302,19 +303,13 @@ func (m Model) renderConflictResolution() string {
 	optionsStr := lipgloss.JoinVertical(lipgloss.Left, formattedOptions...)
 
 	// Create a box for the options
-	optionsBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ui.AccentColor).
-		Padding(1, 2).
-		Width(boxWidth).
-		Align(lipgloss.Left)
+	optionsBox, _ := m.pageBox(ui.AccentColor, 10, 70)
+	optionsBox = optionsBox.Align(lipgloss.Left)
 
 	renderedOptionsBox := optionsBox.Render(optionsStr)
 
 	// Render instructions
-	instructions := lipgloss.NewStyle().
-		Foreground(ui.TextColor).
-		Render("Use Up/Down to select, Enter to confirm")
+	instructions := m.selectInstructions()
 
 	// Combine the content
 	content := lipgloss.JoinVertical(
@@ -336,7 +331,12 @@ func (m Model) renderConflictResolution() string {
 // renderSystemMessages renders the system messages box efficiently
 func (m Model) renderSystemMessages() string {
 	// Calculate dynamic width based on terminal size
-	boxWidth := max(min(m.width-10, 100), 40) // Min 40, max 100, or terminal width - 10
+	// Min 40, max 100, or terminal width - 10
+	boxStyle, boxWidth := m.pageBox(ui.PrimaryColor, 10, 100)
+	if !m.compact() {
+		boxWidth = max(boxWidth, 40)
+		boxStyle = boxStyle.Width(boxWidth)
+	}
 
 	// Add a title for the messages box
 	title := lipgloss.NewStyle().
@@ -344,13 +344,8 @@ func (m Model) renderSystemMessages() string {
 		Bold(true).
 		Render("Command Output")
 
-	// Create box style for messages
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ui.PrimaryColor).
-		Padding(1, 2).
-		Width(boxWidth).
-		Height(15) // Increased height for better visibility
+	// Increased height for better visibility
+	boxStyle = boxStyle.Height(15)
 
 	// If we have a message queue, use it
 	if m.messageQueue != nil && m.messageQueue.Size() > 0 {
@@ -413,8 +408,8 @@ func (m Model) renderSystemMessages() string {
 func (m Model) renderWelcomePage() string {
 	// Use our common page container style
 	pageStyle := PageContainer.Copy().
-		Width(m.width).  // Use full terminal width
-		Height(m.height) // Use full terminal height
+		Width(m.width).          // Use full terminal width
+		Height(m.screenHeight()) // Use full terminal height
 
 	// Create a dynamic title with background that adapts to terminal width
 	titleStyle := TitleStyle.Copy().
@@ -430,34 +425,75 @@ func (m Model) renderWelcomePage() string {
 
 	// Render features with consistent styling
 	features := []string{
-		"• Hyprland compositor with modern UI",
-		"• Carefully selected applications",
-		"• Thoughtful default configuration",
-		"• Easy installation and setup",
+		"Hyprland compositor with modern UI",
+		"Carefully selected applications",
+		"Thoughtful default configuration",
+		"Easy installation and setup",
 	}
 
 	// Calculate box width based on terminal width
-	boxWidth := min(m.width-20, 70)
+	boxStyle, boxWidth := m.pageBox(primaryColor, 20, 70)
+	boxStyle = boxStyle.Align(lipgloss.Center)
 
-	// Style each feature
-	styledFeatures := []string{}
-	for _, feature := range features {
-		styledFeature := lipgloss.NewStyle().
-			Foreground(textColor).
-			Align(lipgloss.Left).
-			Render(feature)
-		styledFeatures = append(styledFeatures, styledFeature)
-	}
-
-	// Join the features with spacing
-	featureList := lipgloss.JoinVertical(lipgloss.Left, styledFeatures...)
+	// Wrap the features to the box
+	featureList := bulletList(features, ui.ClampWidth(boxWidth-4))
 
 	// Create a box for the features using our common content box style
-	boxStyle := ContentBox.Copy().Width(boxWidth)
 	featuresBox := boxStyle.Render(featureList)
 
 	// Render button with clear instruction
 	button := m.renderButton("Press Enter to continue", true)
+	if m.resume != nil {
+		featuresBox = lipgloss.JoinVertical(
+			lipgloss.Center,
+			featuresBox,
+			"",
+			InfoStyle.Render(fmt.Sprintf("%s Resuming the interrupted installation with %s: %d packages left",
+				ui.Symbols.Info, m.resume.AURHelper, len(m.resume.RemainingPackages))),
+		)
+		button = m.renderButton("Press Enter to resume", true)
+	}
+
+	// Warn prominently when running on an unsupported system
+	if m.unsupportedDistro && !m.distroConfirmed {
+		warningLines := []string{
+			WarningStyle.Render(ui.Symbols.Warning + " Unsupported system: " + m.distroName),
+			"",
+		}
+		for _, warning := range m.preflightWarnings {
+			warningLines = append(warningLines, InfoStyle.Render(ui.Symbols.Bullet+" "+warning))
+		}
+		warningLines = append(warningLines,
+			"",
+			InfoStyle.Render("This installer is designed for Arch Linux and may not work here."),
+		)
+
+		warningStyle, _ := m.pageBox(warningColor, 20, 70)
+		warningBox := warningStyle.
+			Align(lipgloss.Center).
+			Render(lipgloss.JoinVertical(lipgloss.Left, warningLines...))
+
+		featuresBox = lipgloss.JoinVertical(lipgloss.Center, featuresBox, "", warningBox)
+		button = m.renderButton("Press y to continue anyway", true)
+	}
+
+	// Explain why the installation can't start
+	if m.homeError != nil {
+		errorStyle, boxWidth := m.pageBox(errorColor, 20, 70)
+		errorBox := errorStyle.
+			Align(lipgloss.Center).
+			Render(lipgloss.JoinVertical(
+				lipgloss.Left,
+				ErrorStyle.Render(ui.Symbols.Cross+" Cannot install"),
+				"",
+				InfoStyle.Copy().Width(ui.ClampWidth(boxWidth-4)).Render(m.homeError.Error()),
+				"",
+				InfoStyle.Render("Backups and dotfiles are written to the home directory."),
+			))
+
+		featuresBox = lipgloss.JoinVertical(lipgloss.Center, featuresBox, "", errorBox)
+		button = m.renderButton("Press q to quit", true)
+	}
 
 	// Combine the content
 	content := lipgloss.JoinVertical(
@@ -494,18 +530,21 @@ func (m Model) renderAURHelperPage() string {
 	// Render options
 	options := []string{}
 	for i, helper := range m.aurHelperOptions {
-		options = append(options, m.renderOption(helper, i == m.aurHelperIndex))
+		options = append(options, m.renderOption(aurHelperLabel(helper), i == m.aurHelperIndex))
 	}
 
 	optionsStr := lipgloss.JoinVertical(lipgloss.Left, options...)
+	if len(m.aurHelperOptions) == 0 {
+		optionsStr = InfoStyle.Render("No AUR helpers available")
+	}
 
 	// Calculate box width based on terminal width
-	boxWidth := min(m.width-20, 60)
-	boxStyle := ContentBox.Copy().Width(boxWidth)
+	boxStyle, _ := m.pageBox(primaryColor, 20, 60)
+	boxStyle = boxStyle.Align(lipgloss.Center)
 	optionsBox := boxStyle.Render(optionsStr)
 
 	// Render instructions
-	instructions := InfoStyle.Render("Use Up/Down to select, Enter to confirm, Esc to go back")
+	instructions := InfoStyle.Render(m.label("Use Up/Down to select, Enter to confirm, Esc to go back", "Up/Down, Enter, Esc back"))
 
 	// Combine the content
 	content := lipgloss.JoinVertical(
@@ -541,7 +580,9 @@ func (m Model) renderPackageCategoriesPage() string {
 
 	// Render categories and options
 	var content string
-	if len(m.categories) > 0 {
+	if m.tagFilter != "" {
+		content = m.renderTaggedOptions()
+	} else if len(m.categories) > 0 {
 		// Render categories
 		categoriesContent := []string{}
 		for i, category := range m.categories {
@@ -596,7 +637,7 @@ func (m Model) renderPackageCategoriesPage() string {
 								// Render checkbox and option name with highlighted search match
 								checkbox := RenderCheckbox(isChecked)
 								highlightedName := ui.HighlightMatch(option.Name, m.searchQuery)
-								optionStr := fmt.Sprintf("%s %s", checkbox, highlightedName)
+								optionStr := fmt.Sprintf("%s %s%s%s%s", checkbox, highlightedName, stabilityBadge(option), buildTimeHint(option), changedMarker(option, isChecked))
 								optionsContent = append(optionsContent, optionStyle.Render(optionStr))
 								break
 							}
@@ -629,7 +670,7 @@ func (m Model) renderPackageCategoriesPage() string {
 
 						// Render checkbox and option name
 						checkbox := RenderCheckbox(isChecked)
-						optionStr := fmt.Sprintf("%s %s", checkbox, option.Name)
+						optionStr := fmt.Sprintf("%s %s%s%s%s", checkbox, option.Name, stabilityBadge(option), buildTimeHint(option), changedMarker(option, isChecked))
 						optionsContent = append(optionsContent, optionStyle.Render(optionStr))
 					}
 				}
@@ -650,10 +691,24 @@ func (m Model) renderPackageCategoriesPage() string {
 	}
 
 	// Calculate box width based on terminal width
-	boxWidth := min(m.width-10, 80)
-	boxStyle := ContentBox.Copy().Width(boxWidth)
+	boxStyle, boxWidth := m.pageBox(primaryColor, 10, 80)
+	boxStyle = boxStyle.Align(lipgloss.Center)
 	contentBox := boxStyle.Render(content)
 
+	// Explain what the focused category is for
+	if m.tagFilter == "" && m.categoryIndex >= 0 && m.categoryIndex < len(m.categories) {
+		if description := m.categories[m.categoryIndex].Description; description != "" {
+			descriptionStyle := InfoStyle.Copy().
+				Width(boxWidth).
+				Align(lipgloss.Center)
+			contentBox = lipgloss.JoinVertical(
+				lipgloss.Center,
+				descriptionStyle.Render(fmt.Sprintf("%s %s: %s", ui.Symbols.Info, m.categories[m.categoryIndex].Name, description)),
+				contentBox,
+			)
+		}
+	}
+
 	// Render instructions
 	var instructions string
 	if m.optionIndex == -1 {
@@ -661,17 +716,91 @@ func (m Model) renderPackageCategoriesPage() string {
 	} else {
 		instructions = InfoStyle.Render("Use Up/Down to navigate, Enter to toggle, Tab to switch to categories, Esc to go back")
 	}
+	resetHint := DimStyle.Render("Press d to reset this category, D to reset all categories to defaults, a for advanced mode, x to add extra packages, t to filter by tag")
+	if m.advancedMode {
+		resetHint = lipgloss.JoinVertical(
+			lipgloss.Center,
+			resetHint,
+			WarningStyle.Render(fmt.Sprintf("Advanced mode: %d base packages deselected", m.countDeselectedBasePackages())),
+		)
+	}
+
+	// Warn about long builds before they start
+	if buildTime := m.selectedBuildTime(); buildTime > 0 {
+		resetHint = lipgloss.JoinVertical(
+			lipgloss.Center,
+			resetHint,
+			DimStyle.Render(fmt.Sprintf("Selected options take about %d minutes to build", int(buildTime.Minutes()))),
+		)
+	}
+
+	// Explain the marker of changed options
+	if changed := m.countChangedOptions(); changed > 0 {
+		resetHint = lipgloss.JoinVertical(
+			lipgloss.Center,
+			resetHint,
+			DimStyle.Render(fmt.Sprintf("%s marks options changed from the defaults (%d)", ui.Symbols.Changed, changed)),
+		)
+	}
+
+	// List the packages the availability check couldn't find
+	if m.checkingAvailability {
+		resetHint = lipgloss.JoinVertical(lipgloss.Center, resetHint, ui.Spinner(m.spinner.View(), "Checking package availability..."))
+	} else if len(m.missingPackages) > 0 {
+		resetHint = lipgloss.JoinVertical(
+			lipgloss.Center,
+			resetHint,
+			WarningStyle.Copy().MaxWidth(m.width).Render("Not found: "+strings.Join(m.missingPackages, ", ")),
+		)
+	}
 
 	// Render search box
-	searchBoxWidth := min(m.width-20, 40)
-	searchBox := ui.SearchBox(m.searchQuery, searchBoxWidth, m.searchFocused)
+	searchBoxWidth := ui.ClampWidth(min(m.width-20, 40))
+	searchBox := ui.SearchBoxWithCompletion(m.searchQuery, m.searchCompletion(), searchBoxWidth, m.searchFocused)
+
+	// Show how many options match the search query
+	if m.searchQuery != "" {
+		var matchCount string
+		switch len(m.filteredOptions) {
+		case 0:
+			matchCount = WarningStyle.Render("No matches")
+		case 1:
+			matchCount = InfoStyle.Render("1 match")
+		default:
+			matchCount = InfoStyle.Render(fmt.Sprintf("%d matches", len(m.filteredOptions)))
+		}
+		searchBox = lipgloss.JoinHorizontal(lipgloss.Center, searchBox, "  ", matchCount)
+	}
 
 	// Add search instructions if search is focused
 	var searchInstructions string
 	if m.searchFocused {
 		searchInstructions = lipgloss.NewStyle().
 			Foreground(ui.DimmedColor).
-			Render("Type to search, Esc to cancel, Enter to confirm")
+			Render("Type to search, Tab to complete, Esc to cancel, Enter to confirm")
+	}
+
+	// Render the extra packages while editing them or once some are added
+	if m.extraFocused || len(m.extraPackages) > 0 {
+		extraValue := strings.Join(m.extraPackages, ", ")
+		if m.extraFocused {
+			extraValue = m.extraInput
+		}
+		searchBox = lipgloss.JoinVertical(
+			lipgloss.Center,
+			searchBox,
+			ui.InputBox("Extra: ", extraValue, searchBoxWidth, m.extraFocused),
+		)
+		if m.extraFocused {
+			searchInstructions = lipgloss.NewStyle().
+				Foreground(ui.DimmedColor).
+				Render("Type package names separated by commas, Enter to confirm, Esc to cancel")
+		}
+	}
+
+	// The confirmation replaces the instructions until it is answered
+	if m.confirmingInstall {
+		instructions = m.renderInstallConfirmation()
 	}
 
 	// Combine the content
@@ -686,6 +815,7 @@ func (m Model) renderPackageCategoriesPage() string {
 		contentBox,
 		"",
 		instructions,
+		resetHint,
 	)
 
 	// Return the centered content
@@ -716,23 +846,67 @@ func (m Model) renderInstallationPage() string {
 		return m.renderBackupConfirmation()
 	}
 
-	// Render progress
-	progressPercentage := 0
-	if m.totalSteps > 0 {
-		progressPercentage = (m.installProgress * 100) / m.totalSteps
+	// If we're in the overwrite confirmation phase
+	if m.installPhase == "overwrite_confirmation" {
+		return m.renderOverwriteConfirmation()
+	}
+
+	// If we're in the wallpaper selection phase
+	if m.installPhase == "wallpaper_selection" {
+		return m.renderWallpaperSelection()
 	}
 
+	// If we're in the services confirmation phase
+	if m.installPhase == "services_confirmation" {
+		return m.renderServicesConfirmation()
+	}
+
+	// Render progress
+	progressPercentage := m.progressPercentage()
+
 	// Calculate progress bar width based on terminal width
-	progressBarWidth := min(m.width-10, 80)
+	progressBarWidth := ui.ClampWidth(min(m.width-10, 80))
 
 	// Create a more visually appealing progress bar
 	progressBar := m.RenderProgressBar(progressBarWidth, progressPercentage)
 	progressText := fmt.Sprintf("%d/%d (%d%%)", m.installProgress, m.totalSteps, progressPercentage)
 
+	// Show the aggregate download progress once pacman has reported sizes
+	if m.downloadTotal > 0 {
+		downloadPercentage := int(m.downloadedBytes * 100 / m.downloadTotal)
+		downloadText := fmt.Sprintf("Downloaded %s of %s",
+			utils.FormatBytes(m.downloadedBytes), utils.FormatBytes(m.downloadTotal))
+		progressText = lipgloss.JoinVertical(
+			lipgloss.Center,
+			progressText,
+			"",
+			ui.ProgressIndicator(progressBarWidth, downloadPercentage, downloadText),
+		)
+	}
+
+	// Show the progress of a running git clone
+	if m.cloning {
+		cloneText := fmt.Sprintf("Cloning: %s %d%%", m.cloneStage, m.clonePercent)
+		progressText = lipgloss.JoinVertical(
+			lipgloss.Center,
+			progressText,
+			"",
+			ui.ProgressIndicator(progressBarWidth, m.clonePercent, cloneText),
+		)
+	}
+
 	// Render current step with animated spinner
 	var currentStep string
 	if m.errorMessage != "" {
-		currentStep = ErrorStyle.Render(m.errorMessage)
+		hint := "Press s to open a shell and fix the problem, or b to write a diagnostics report for a bug report"
+		if m.retryStep != "" {
+			hint = "Press r to retry, s to open a shell, or b to write a bug report"
+		}
+		currentStep = lipgloss.JoinVertical(
+			lipgloss.Center,
+			ErrorStyle.Render(m.errorMessage),
+			DimStyle.Render(hint),
+		)
 	} else {
 		// Use a more visible spinner
 		spinnerText := m.spinner.View()
@@ -745,7 +919,11 @@ func (m Model) renderInstallationPage() string {
 			stepText = InfoStyle.Render(stepText)
 		}
 
-		currentStep = fmt.Sprintf("%s %s", spinnerText, stepText)
+		currentStep = lipgloss.JoinVertical(
+			lipgloss.Center,
+			fmt.Sprintf("%s %s", spinnerText, stepText),
+			DimStyle.Render("Press Ctrl+C twice within 2 seconds to force quit if the installer stops responding"),
+		)
 	}
 
 	// Render phase with better styling
@@ -762,6 +940,8 @@ func (m Model) renderInstallationPage() string {
 	switch m.installPhase {
 	case "AUR Helper Installation":
 		phaseDescription = "Installing the AUR helper to enable access to the Arch User Repository"
+	case "Package Download":
+		phaseDescription = "Downloading packages from the official repositories before installing them"
 	case "Package Installation":
 		phaseDescription = "Installing selected packages from official repositories and AUR"
 	case "Backup":
@@ -778,13 +958,18 @@ func (m Model) renderInstallationPage() string {
 
 	phaseInfo := phaseInfoStyle.Render(phaseDescription)
 
+	// Show which category the current package was selected from
+	if m.installPhase == "Package Installation" && m.currentCategory != "" {
+		categoryInfo := phaseInfoStyle.Copy().
+			Foreground(ui.AccentColor).
+			Bold(true).
+			Render(m.currentCategory)
+		phaseInfo = lipgloss.JoinVertical(lipgloss.Center, phaseInfo, categoryInfo)
+	}
+
 	// Create a box for the progress information
-	progressBox := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ui.PrimaryColor).
-		Padding(1, 2).
-		Width(min(m.width-10, 80)).
-		Align(lipgloss.Center)
+	progressBox, _ := m.pageBox(ui.PrimaryColor, 10, 80)
+	progressBox = progressBox.Align(lipgloss.Center)
 
 	// Combine the progress elements
 	progressContent := lipgloss.JoinVertical(
@@ -801,12 +986,8 @@ func (m Model) renderInstallationPage() string {
 	// Add task progress if there are any tasks
 	if len(m.tasks) > 0 {
 		// Create a box for the tasks
-		taskBox := lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(ui.AccentColor).
-			Padding(1, 2).
-			Width(min(m.width-10, 80)).
-			Align(lipgloss.Left)
+		taskBox, _ := m.pageBox(ui.AccentColor, 10, 80)
+		taskBox = taskBox.Align(lipgloss.Left)
 
 		// Render the tasks
 		taskContent := m.renderTasks()
@@ -872,13 +1053,8 @@ func (m Model) renderDotfilesConfirmation() string {
 	title := titleStyle.Render("Dotfiles Installation")
 
 	// Calculate box width based on terminal width
-	boxWidth := min(m.width-20, 60)
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(primaryColor).
-		Padding(1, 2).
-		Width(boxWidth).
-		Align(lipgloss.Center)
+	boxStyle, boxWidth := m.pageBox(primaryColor, 20, 60)
+	boxStyle = boxStyle.Align(lipgloss.Center)
 
 	// Create the message
 	messageStyle := SubtitleStyle.Copy().
@@ -887,15 +1063,27 @@ func (m Model) renderDotfilesConfirmation() string {
 	message := messageStyle.Render("Do you want to install the dotfiles?")
 
 	// Render options
+	yes, no := "Yes", "No"
+	if m.liveSession != "" {
+		yes, no = "Yes, install now", "No, install later from a TTY"
+	}
 	options := []string{
-		m.renderOption("Yes", m.dotfilesConfirmation),
-		m.renderOption("No", !m.dotfilesConfirmation),
+		m.renderOption(yes, m.dotfilesConfirmation),
+		m.renderOption(no, !m.dotfilesConfirmation),
 	}
 
 	optionsStr := lipgloss.JoinVertical(lipgloss.Center, options...)
 
+	// Warn that the running session picks up the new config files as they are copied
+	if m.liveSession != "" {
+		warning := WarningStyle.Copy().
+			Width(ui.ClampWidth(boxWidth - 6)).
+			Render(fmt.Sprintf("%s You are running inside a %s session. Installing the dotfiles now replaces its configuration while it runs, so bars and windows may disappear until you log out or reload.", ui.Symbols.Warning, m.liveSession))
+		message = lipgloss.JoinVertical(lipgloss.Center, message, "", warning)
+	}
+
 	// Render instructions
-	instructions := InfoStyle.Render("Use Up/Down to select, Enter to confirm")
+	instructions := m.selectInstructions()
 
 	// Combine the content
 	confirmationContent := lipgloss.JoinVertical(
@@ -937,13 +1125,8 @@ func (m Model) renderBackupConfirmation() string {
 	title := titleStyle.Render("Backup Configuration")
 
 	// Calculate box width based on terminal width
-	boxWidth := min(m.width-20, 70)
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(primaryColor).
-		Padding(1, 2).
-		Width(boxWidth).
-		Align(lipgloss.Center)
+	boxStyle, boxWidth := m.pageBox(primaryColor, 20, 70)
+	boxStyle = boxStyle.Align(lipgloss.Center)
 
 	// Create the message with better formatting
 	messageStyle := SubtitleStyle.Copy().
@@ -952,25 +1135,14 @@ func (m Model) renderBackupConfirmation() string {
 	messageHeader := messageStyle.Render("Do you want to backup your existing configuration directories before installing dotfiles?")
 
 	// Format the directories list
-	dirsList := []string{
-		"• .config",
-		"• .local",
-		"• .ags",
-	}
+	dirListStr := bulletList([]string{".config", ".local", ".ags"}, ui.ClampWidth(boxWidth-4))
 
-	styledDirs := []string{}
-	for _, dir := range dirsList {
-		styledDir := lipgloss.NewStyle().
-			Foreground(textColor).
-			Align(lipgloss.Left).
-			Render(dir)
-		styledDirs = append(styledDirs, styledDir)
+	// Add the backup location info, each backup gets its own directory
+	location := fmt.Sprintf("Backups will be stored in a new timestamped directory in ~/%s/", config.BackupDir)
+	if m.options.BackupLabel != "" {
+		location = fmt.Sprintf("Backups will be stored in ~/%s/, labelled %s", config.BackupDir, m.options.BackupLabel)
 	}
-
-	dirListStr := lipgloss.JoinVertical(lipgloss.Left, styledDirs...)
-
-	// Add the backup location info
-	backupLocation := InfoStyle.Render("Backups will be stored in ~/HyprLuna-User-Bak/")
+	backupLocation := InfoStyle.Render(location)
 
 	// Render options
 	options := []string{
@@ -981,7 +1153,7 @@ func (m Model) renderBackupConfirmation() string {
 	optionsStr := lipgloss.JoinVertical(lipgloss.Center, options...)
 
 	// Render instructions
-	instructions := InfoStyle.Render("Use Up/Down to select, Enter to confirm")
+	instructions := m.selectInstructions()
 
 	// Combine the content
 	confirmationContent := lipgloss.JoinVertical(
@@ -1013,6 +1185,227 @@ func (m Model) renderBackupConfirmation() string {
 	return pageStyle.Render(content)
 }
 
+// renderOverwriteConfirmation renders the prompt before deleting an existing checkout
+func (m Model) renderOverwriteConfirmation() string {
+	// Use our common page container style
+	pageStyle := PageContainer.Copy().
+		Width(m.width) // Use full terminal width
+
+	// Create a dynamic title with background that adapts to terminal width
+	titleStyle := TitleStyle.Copy().
+		Width(min(m.width, 80)).
+		Align(lipgloss.Center).
+		Bold(true)
+
+	title := titleStyle.Render("Existing HyprLuna Directory")
+
+	// Calculate box width based on terminal width
+	boxStyle, _ := m.pageBox(warningColor, 20, 60)
+	boxStyle = boxStyle.Align(lipgloss.Center)
+
+	// Create the message
+	messageStyle := SubtitleStyle.Copy().
+		Align(lipgloss.Center)
+
+	message := messageStyle.Render("~/HyprLuna already exists and will be deleted before cloning. Continue?")
+	warning := WarningStyle.Render("Any local changes in ~/HyprLuna will be lost")
+
+	// Render options
+	options := []string{
+		m.renderOption("Yes", m.overwriteConfirmation),
+		m.renderOption("No", !m.overwriteConfirmation),
+	}
+
+	optionsStr := lipgloss.JoinVertical(lipgloss.Center, options...)
+
+	// Render instructions
+	instructions := m.selectInstructions()
+
+	// Combine the content
+	confirmationContent := lipgloss.JoinVertical(
+		lipgloss.Center,
+		message,
+		warning,
+		"",
+		optionsStr,
+		"",
+		instructions,
+	)
+
+	// Render the box
+	renderedBox := boxStyle.Render(confirmationContent)
+
+	// Combine everything
+	content := lipgloss.JoinVertical(
+		lipgloss.Center,
+		title,
+		"",
+		renderedBox,
+	)
+
+	// Return the centered content
+	return pageStyle.Render(content)
+}
+
+// renderWallpaperSelection renders the wallpaper choice used to generate the color scheme
+func (m Model) renderWallpaperSelection() string {
+	// Use our common page container style
+	pageStyle := PageContainer.Copy().
+		Width(m.width) // Use full terminal width
+
+	// Create a dynamic title with background that adapts to terminal width
+	titleStyle := TitleStyle.Copy().
+		Width(min(m.width, 80)).
+		Align(lipgloss.Center).
+		Bold(true)
+
+	title := titleStyle.Render("Choose a Wallpaper")
+
+	// Calculate box width based on terminal width
+	boxStyle, _ := m.pageBox(primaryColor, 20, 70)
+	boxStyle = boxStyle.Align(lipgloss.Center)
+
+	// Create the message
+	messageStyle := SubtitleStyle.Copy().
+		Align(lipgloss.Center)
+
+	messageHeader := messageStyle.Render("Which wallpaper should the color scheme be generated from?")
+
+	// Show a window of entries around the selection
+	names := []string{"Random"}
+	for _, wallpaper := range m.wallpapers {
+		names = append(names, filepath.Base(wallpaper))
+	}
+
+	const visible = 10
+	start := max(0, min(m.wallpaperIndex-visible/2, len(names)-visible))
+	end := min(len(names), start+visible)
+
+	options := []string{}
+	for i := start; i < end; i++ {
+		options = append(options, m.renderOption(names[i], i == m.wallpaperIndex))
+	}
+
+	optionsStr := lipgloss.JoinVertical(lipgloss.Center, options...)
+
+	// Render position and instructions
+	position := DimStyle.Render(fmt.Sprintf("%d of %d", m.wallpaperIndex+1, len(names)))
+	instructions := m.selectInstructions()
+
+	// Combine the content
+	selectionContent := lipgloss.JoinVertical(
+		lipgloss.Center,
+		messageHeader,
+		"",
+		optionsStr,
+		position,
+		"",
+		instructions,
+	)
+
+	// Render the box
+	renderedBox := boxStyle.Render(selectionContent)
+
+	// Combine everything
+	content := lipgloss.JoinVertical(
+		lipgloss.Center,
+		title,
+		"",
+		renderedBox,
+	)
+
+	// Return the centered content
+	return pageStyle.Render(content)
+}
+
+// renderServicesConfirmation renders the prompt before enabling systemd services
+func (m Model) renderServicesConfirmation() string {
+	// Use our common page container style
+	pageStyle := PageContainer.Copy().
+		Width(m.width) // Use full terminal width
+
+	// Create a dynamic title with background that adapts to terminal width
+	titleStyle := TitleStyle.Copy().
+		Width(min(m.width, 80)).
+		Align(lipgloss.Center).
+		Bold(true)
+
+	title := titleStyle.Render("Enable Services")
+
+	// Calculate box width based on terminal width
+	boxStyle, _ := m.pageBox(primaryColor, 20, 70)
+	boxStyle = boxStyle.Align(lipgloss.Center)
+
+	// Create the message
+	messageStyle := SubtitleStyle.Copy().
+		Align(lipgloss.Center)
+
+	messageHeader := messageStyle.Render("Do you want to enable the following services?")
+
+	// Format the services list, required services are started right away
+	styledServices := []string{}
+	for _, service := range m.options.Services {
+		styledServices = append(styledServices, lipgloss.NewStyle().
+			Foreground(textColor).
+			Align(lipgloss.Left).
+			Render(ui.Symbols.Bullet+" "+service+" (start now)"))
+	}
+	displayManagers := m.selectedServices()
+	for _, service := range displayManagers {
+		styledServices = append(styledServices, lipgloss.NewStyle().
+			Foreground(textColor).
+			Align(lipgloss.Left).
+			Render(ui.Symbols.Bullet+" "+service+" (at next boot)"))
+	}
+
+	servicesListStr := lipgloss.JoinVertical(lipgloss.Left, styledServices...)
+
+	// Add the session entry info
+	sessionInfo := ""
+	if len(displayManagers) > 0 {
+		sessionInfo = InfoStyle.Render(fmt.Sprintf("A HyprLuna session entry will be added to %s", config.SessionFile))
+	}
+
+	// Render options
+	options := []string{
+		m.renderOption("Yes", m.servicesConfirmation),
+		m.renderOption("No", !m.servicesConfirmation),
+	}
+
+	optionsStr := lipgloss.JoinVertical(lipgloss.Center, options...)
+
+	// Render instructions
+	instructions := m.selectInstructions()
+
+	// Combine the content
+	confirmationContent := lipgloss.JoinVertical(
+		lipgloss.Center,
+		messageHeader,
+		"",
+		servicesListStr,
+		"",
+		sessionInfo,
+		"",
+		optionsStr,
+		"",
+		instructions,
+	)
+
+	// Render the box
+	renderedBox := boxStyle.Render(confirmationContent)
+
+	// Combine everything
+	content := lipgloss.JoinVertical(
+		lipgloss.Center,
+		title,
+		"",
+		renderedBox,
+	)
+
+	// Return the centered content
+	return pageStyle.Render(content)
+}
+
 // renderCompletePage renders the complete page
 func (m Model) renderCompletePage() string {
 	// Use our common page container style
@@ -1032,27 +1425,79 @@ func (m Model) renderCompletePage() string {
 
 	message := messageStyle.Render("HyprLuna has been successfully installed on your system!")
 
-	// Render instructions
-	instructions := []string{
-		"• Log out of your current session",
-		"• Select HyprLuna from your display manager",
-		"• Your configuration files have been installed",
-		"• If you chose to backup, your original files are in ~/HyprLuna-User-Bak/",
-		"• Enjoy your new desktop environment!",
+	// Show the installed Hyprland version once it is known, for bug reports
+	if m.hyprlandVersion != "" {
+		message = lipgloss.JoinVertical(
+			lipgloss.Center,
+			message,
+			InfoStyle.Render(fmt.Sprintf("Hyprland %s", m.hyprlandVersion)),
+		)
 	}
 
-	instructionsStr := lipgloss.JoinVertical(
-		lipgloss.Left,
-		instructions...,
-	)
+	// Show the disk space the installation took
+	if disk := m.diskSummary(); disk != "" {
+		message = lipgloss.JoinVertical(
+			lipgloss.Center,
+			message,
+			DimStyle.Render(disk),
+		)
+	}
 
 	// Calculate box width based on terminal width
-	boxWidth := min(m.width-20, 70)
-	boxStyle := ContentBox.Copy().Width(boxWidth)
+	boxStyle, boxWidth := m.pageBox(primaryColor, 20, 70)
+	boxStyle = boxStyle.Align(lipgloss.Center)
+
+	// Render instructions wrapped to the box, preferring the ones shipped
+	// with the dotfiles, which are shown as written
+	backupNote := "If you chose to backup, your original files are in ~/" + config.BackupDir + "/"
+	if m.backupDir != "" {
+		backupNote = "Your original files are backed up in " + m.backupDir
+	}
+	instructionsStr := bulletList([]string{
+		"Log out of your current session",
+		"Select HyprLuna from your display manager",
+		"Your configuration files have been installed",
+		backupNote,
+		"Enjoy your new desktop environment!",
+	}, ui.ClampWidth(boxWidth-4))
+	if len(m.instructions) > 0 {
+		var lines []string
+		for _, line := range m.instructions {
+			lines = append(lines, wrapText(line, ui.ClampWidth(boxWidth-4))...)
+		}
+		instructionsStr = lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
 	instructionsBox := boxStyle.Render(instructionsStr)
 
-	// Render button
+	// Render the time spent in each phase
+	if timeline := m.renderPhaseTimeline(); timeline != "" {
+		instructionsBox = lipgloss.JoinVertical(
+			lipgloss.Center,
+			boxStyle.Render(timeline),
+			"",
+			instructionsBox,
+		)
+	}
+
+	// Render the buttons, offering to retry the packages that failed
 	button := m.renderButton("Exit", true)
+	if len(m.failedPackages) > 0 {
+		button = lipgloss.JoinHorizontal(
+			lipgloss.Center,
+			m.renderButton(fmt.Sprintf("Retry failed (%d)", len(m.failedPackages)), m.completeRetry),
+			m.renderButton("Exit", !m.completeRetry),
+		)
+
+		failed := WarningStyle.Copy().
+			Width(min(m.width, 80)).
+			Align(lipgloss.Center).
+			Render(fmt.Sprintf("%d packages failed to install: %s", len(m.failedPackages), strings.Join(m.failedPackages, ", ")))
+		message = lipgloss.JoinVertical(lipgloss.Center, message, failed)
+	}
+
+	// Render the copy hint
+	copyHint := DimStyle.Render("Press l to view the log, c to copy the command output to the clipboard, b to write a bug report")
+	commandHint := DimStyle.Render(fmt.Sprintf("Press %s to copy the packages as an install command", m.keyMap.CopyCommand.Help().Key))
 
 	// Combine the content
 	content := lipgloss.JoinVertical(
@@ -1064,12 +1509,87 @@ func (m Model) renderCompletePage() string {
 		instructionsBox,
 		"",
 		button,
+		copyHint,
+		commandHint,
 	)
 
 	// Return the centered content
 	return pageStyle.Render(content)
 }
 
+// countDeselectedBasePackages returns how many base packages are deselected in advanced mode
+func (m Model) countDeselectedBasePackages() int {
+	count := 0
+	for _, deselected := range m.deselectedBasePackages {
+		if deselected {
+			count++
+		}
+	}
+	return count
+}
+
+// aurHelperLabel returns the display label for an AUR helper option
+func aurHelperLabel(name string) string {
+	if name == aur.PacmanOnly {
+		return "None (pacman only)"
+	}
+	return name
+}
+
+// stabilityBadge returns the badge shown after the name of option, if any
+func stabilityBadge(option config.PackageOption) string {
+	switch {
+	case option.Experimental():
+		return " " + WarningStyle.Render("["+string(config.StabilityExperimental)+"]")
+	case option.Stability == config.StabilityRecommended:
+		return " " + SuccessStyle.Render("["+string(config.StabilityRecommended)+"]")
+	}
+	return ""
+}
+
+// buildTimeHint returns the hint shown next to options that take long to build
+func buildTimeHint(option config.PackageOption) string {
+	switch buildTime := option.EstimatedBuildTime(); buildTime {
+	case config.BuildTimeMedium, config.BuildTimeSlow:
+		return " " + DimStyle.Render("(~ "+string(buildTime)+" build)")
+	}
+	return ""
+}
+
+// selectedBuildTime returns the rough total build time of the selected options
+func (m Model) selectedBuildTime() time.Duration {
+	var total time.Duration
+	for _, category := range m.categories {
+		for _, option := range category.Options {
+			if slices.Contains(m.selectedOptions[category.Name], option.Name) {
+				total += option.EstimatedBuildTime().Estimate()
+			}
+		}
+	}
+	return total
+}
+
+// changedMarker returns the marker of an option whose selection differs from its default
+func changedMarker(option config.PackageOption, checked bool) string {
+	if checked == option.Default {
+		return ""
+	}
+	return " " + SelectionStyle.Render(ui.Symbols.Changed)
+}
+
+// countChangedOptions returns how many options differ from their default selection
+func (m Model) countChangedOptions() int {
+	count := 0
+	for _, category := range m.categories {
+		for _, option := range category.Options {
+			if slices.Contains(m.selectedOptions[category.Name], option.Name) != option.Default {
+				count++
+			}
+		}
+	}
+	return count
+}
+
 // renderOption renders an option with selection indicator
 func (m Model) renderOption(text string, selected bool) string {
 	return ui.Option(text, selected)
@@ -1082,37 +1602,33 @@ func (m Model) renderButton(text string, selected bool) string {
 
 // renderHelpDropdown renders the help content as a dropdown
 func (m Model) renderHelpDropdown() string {
-	// Calculate box width based on terminal width
-	boxWidth := min(m.width-10, 80)
-
-	// Create a styled box for the help content
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(ui.AccentColor).
-		Padding(1, 2).
-		Width(boxWidth).
-		Align(lipgloss.Left)
+	// Create a styled box for the help content, adjusted to the terminal width
+	boxStyle, boxWidth := m.pageBox(ui.AccentColor, 10, 80)
+	boxStyle = boxStyle.Align(lipgloss.Left)
 
 	// Create the help content
 	var helpContent strings.Builder
 	helpContent.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ui.PrimaryColor).Render("Keyboard Controls:"))
 	helpContent.WriteString("\n\n")
 
-	// Add key bindings in a more readable format
-	keyBindings := []struct {
+	// List the configured key bindings, so remapped keys show up as they are
+	type keyHelp struct {
 		key         string
 		description string
-	}{
-		{"↑/k", "Move up"},
-		{"↓/j", "Move down"},
-		{"←/h", "Move left/back"},
-		{"→/l", "Move right/forward"},
-		{"Enter/Space", "Select/Confirm"},
-		{"Tab", "Switch focus"},
-		{"Esc", "Go back"},
-		{"q/Ctrl+C", "Quit"},
-		{"?", "Toggle help"},
 	}
+	var keyBindings []keyHelp
+	for _, group := range m.keyMap.FullHelp() {
+		for _, binding := range group {
+			help := binding.Help()
+			if !binding.Enabled() || help.Desc == "" {
+				continue
+			}
+			keyBindings = append(keyBindings, keyHelp{help.Key, strings.ToUpper(help.Desc[:1]) + help.Desc[1:]})
+		}
+	}
+
+	// Force quitting isn't a key binding, see ForceQuitWindow
+	keyBindings = append(keyBindings, keyHelp{"ctrl+c ×2", "Force quit if the installer hangs"})
 
 	// Format key bindings in two columns
 	for _, kb := range keyBindings {
@@ -1124,7 +1640,7 @@ func (m Model) renderHelpDropdown() string {
 
 		descStyle := lipgloss.NewStyle().
 			Foreground(ui.TextColor).
-			Width(boxWidth - 20)
+			Width(ui.ClampWidth(boxWidth - 20))
 
 		line := lipgloss.JoinHorizontal(
 			lipgloss.Left,
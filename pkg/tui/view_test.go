@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	"github.com/Lunaris-Project/lunaris-installer/pkg/tui/ui"
+)
+
+// viewStates returns models showing each page, and the prompts of the
+// installation page, at width and height
+func viewStates(width, height int) map[string]Model {
+	base := NewModelWithOptions(config.DefaultOptions())
+	base.width, base.height = width, height
+	base.aurHelper = aur.NewHelper(aur.PacmanOnly)
+	base.AddInfoNotification("Notification", "A notification to render")
+
+	// The router is shared by copies of the model, so each page gets its own
+	onPage := func(page Page) Model {
+		m := base
+		router := *base.router
+		router.currentPage = page
+		m.router = &router
+		m.page = page
+		return m
+	}
+
+	states := make(map[string]Model)
+	for _, page := range []Page{WelcomePage, AURHelperPage, PackageCategoriesPage, InstallationPage, CompletePage, FailedPage, LogPage} {
+		states[fmt.Sprintf("page %d", page)] = onPage(page)
+	}
+
+	installing := onPage(InstallationPage)
+	installing.totalSteps, installing.installProgress = 10, 3
+	installing.stepWeights = nil
+	installing.currentStep = "Installing pkg-a..."
+	states["installation progress"] = installing
+
+	for _, phase := range []string{"dotfiles_confirmation", "backup_confirmation", "overwrite_confirmation", "wallpaper_selection", "services_confirmation"} {
+		m := installing
+		m.installPhase = phase
+		states[phase] = m
+	}
+
+	downloading := installing
+	downloading.downloadedBytes, downloading.downloadTotal = 512, 1024
+	downloading.cloning = true
+	downloading.clonePercent = 40
+	states["download and clone progress"] = downloading
+
+	password := installing
+	password.awaitingPassword = true
+	states["password"] = password
+
+	conflict := installing
+	conflict.hasConflict = true
+	conflict.conflictPackage = "pkg-a"
+	conflict.conflictMessage = "pkg-a and pkg-b are in conflict"
+	states["conflict"] = conflict
+
+	failed := onPage(FailedPage)
+	failed.errorMessage = "failed to install pkg-a"
+	states["failed with error"] = failed
+
+	help := installing
+	help.showHelp = true
+	states["help"] = help
+
+	tasks := installing
+	tasks.beginPhase(phaseAURHelper)
+	tasks.beginPhase(phaseDownload)
+	states["tasks"] = tasks
+
+	return states
+}
+
+func TestViewAtTinySizes(t *testing.T) {
+	for _, size := range [][2]int{{0, 0}, {1, 1}, {5, 3}, {12, 8}, {30, 10}} {
+		for name, m := range viewStates(size[0], size[1]) {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Errorf("View() of %s at %dx%d panicked: %v", name, size[0], size[1], r)
+					}
+				}()
+				m.View()
+			}()
+		}
+	}
+}
+
+func TestHelpDropdownShowsConfiguredKeys(t *testing.T) {
+	keyMap, err := DefaultKeyMap().WithOverrides(map[string][]string{"retry": {"R"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewModelWithOptions(config.DefaultOptions())
+	m.width, m.height = 120, 60
+	m.keyMap = keyMap
+
+	help := m.renderHelpDropdown()
+	if !strings.Contains(help, "R ") || !strings.Contains(help, "Retry a failed step") {
+		t.Errorf("help doesn't show the remapped retry key:\n%s", help)
+	}
+	if strings.Contains(help, "  r  ") {
+		t.Errorf("help still shows the default retry key:\n%s", help)
+	}
+}
+
+func TestFooterHintsShowConfiguredKeys(t *testing.T) {
+	opts := config.DefaultOptions()
+	opts.KeyBindings = map[string][]string{"retry": {"R"}}
+	m := NewModelWithOptions(opts)
+
+	route, ok := m.router.GetRoute(CompletePage)
+	if !ok {
+		t.Fatal("no route for the complete page")
+	}
+	if !slices.Contains(route.Hints, "R retry failed") {
+		t.Errorf("hints don't show the remapped retry key: %q", route.Hints)
+	}
+	if slices.Contains(route.Hints, "r retry failed") {
+		t.Errorf("hints still show the default retry key: %q", route.Hints)
+	}
+}
+
+func TestInstallationPageSpinsRunningTask(t *testing.T) {
+	m := viewStates(100, 60)["tasks"]
+	view := m.renderInstallationPage()
+
+	if !strings.Contains(view, m.spinner.View()+" "+phaseDownload) {
+		t.Errorf("the running phase isn't shown with the spinner:\n%s", view)
+	}
+	if !strings.Contains(view, ui.Symbols.Check+" "+phaseAURHelper) {
+		t.Errorf("the finished phase isn't shown as done:\n%s", view)
+	}
+}
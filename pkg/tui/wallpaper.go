@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wallpaperExtensions are the image types offered on the wallpaper selection
+var wallpaperExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".webp": true,
+	".gif":  true,
+}
+
+// findWallpapers returns the images in dir, sorted by name
+func findWallpapers(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var wallpapers []string
+	for _, entry := range entries {
+		if entry.IsDir() || !wallpaperExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		wallpapers = append(wallpapers, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(wallpapers)
+
+	return wallpapers
+}
+
+// wallpaperScriptPath returns the path of the color generation script in homeDir
+func wallpaperScriptPath(homeDir string) string {
+	return filepath.Join(homeDir, ".config", "ags", "scripts", "color_generation", "wallpapers.sh")
+}
+
+// wallpaperDir returns the directory wallpapers are offered from
+func wallpaperDir(homeDir string) string {
+	return filepath.Join(homeDir, config.WallpapersDir)
+}
+
+// moveWallpaperSelection moves the wallpaper selection by delta, wrapping around.
+// Index 0 is a random wallpaper, the rest index into m.wallpapers.
+func (m *Model) moveWallpaperSelection(delta int) {
+	count := len(m.wallpapers) + 1
+	m.wallpaperIndex = ((m.wallpaperIndex+delta)%count + count) % count
+}
+
+// generateWallpaperColors runs the wallpaper script with the selected wallpaper
+func (m *Model) generateWallpaperColors() tea.Cmd {
+	script := m.wallpaperScript
+	progress, total := m.installProgress, m.totalSteps
+
+	// Random keeps the script's default behavior
+	args := []string{script, "-r"}
+	choice := "a random wallpaper"
+	if m.wallpaperIndex > 0 && m.wallpaperIndex <= len(m.wallpapers) {
+		wallpaper := m.wallpapers[m.wallpaperIndex-1]
+		args = []string{script, wallpaper}
+		choice = filepath.Base(wallpaper)
+	}
+
+	return func() tea.Msg {
+		progressMsg := NewInstallProgressMsg(
+			progress,
+			total,
+			"Finishing installation...",
+			"Post-Installation",
+			nil,
+		)
+		progressMsg.Source = "dotfiles"
+
+		if err := exec.Command("sh", args...).Run(); err != nil {
+			progressMsg.Output = []string{fmt.Sprintf("Failed to generate wallpaper colors for %s: %v", choice, err)}
+		} else {
+			progressMsg.Output = []string{fmt.Sprintf("Generated wallpaper colors for %s", choice)}
+		}
+
+		return progressMsg
+	}
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Lunaris-Project/lunaris-installer/pkg/aur"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrStalled is returned when the installation makes no progress for longer
+// than the configured idle timeout
+var ErrStalled = errors.New("installation stalled")
+
+// watchdogInterval is how often the watchdog checks for progress
+const watchdogInterval = 5 * time.Second
+
+// watchdogTickMsg asks the watchdog to check for progress
+type watchdogTickMsg struct{}
+
+// tickWatchdog returns a command to check for progress after watchdogInterval
+func tickWatchdog() tea.Cmd {
+	return tea.Tick(watchdogInterval, func(time.Time) tea.Msg {
+		return watchdogTickMsg{}
+	})
+}
+
+// StallError returns the error the watchdog aborted the installation with,
+// or nil when it didn't
+func (m Model) StallError() error {
+	return m.stallErr
+}
+
+// handleWatchdogTick aborts the installation once neither progress, command
+// output nor input has been seen for the idle timeout, so unattended runs
+// fail instead of waiting forever on a prompt
+func (m Model) handleWatchdogTick() (tea.Model, tea.Cmd) {
+	// Only watch an installation that is still running
+	if m.router.CurrentPage() != InstallationPage || m.errorMessage != "" || m.stallErr != nil {
+		return m, nil
+	}
+
+	idle := time.Since(m.lastActivity)
+	if idle < m.options.IdleTimeout {
+		return m, tickWatchdog()
+	}
+
+	step := m.currentStep
+	if m.awaitingPassword {
+		step = "waiting for the sudo password"
+	} else if m.hasConflict {
+		step = "waiting for a package conflict to be resolved"
+	}
+	err := fmt.Errorf("%w: no progress for %s during %s (%s)", ErrStalled, idle.Round(time.Second), m.installPhase, step)
+
+	// The step's own result arrives later and is ignored, see handleInstallProgress
+	aur.KillPackageManager()
+	m.stallErr = err
+	m.endPhase(err)
+	m.errorMessage = err.Error()
+	m.AddErrorMessage(err.Error(), "watchdog")
+
+	// Nobody is there to act on the failure, so quit once the summary is written
+	if m.options.AssumeYes {
+		return m, tea.Sequence(m.writeSummaryCmd(err), m.quit())
+	}
+
+	next, navCmd := m.router.Navigate(FailedPage, m)
+	return next, tea.Batch(navCmd, m.writeSummaryCmd(err))
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Size assumed when the terminal never reports one
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+
+	// windowSizeTimeout is how long to wait for the terminal size before assuming the default
+	windowSizeTimeout = 2 * time.Second
+)
+
+// windowSizeTimeoutMsg is sent when the terminal size may never arrive
+type windowSizeTimeoutMsg struct{}
+
+// waitForWindowSize returns a command that reports when the terminal took too long to report its size
+func waitForWindowSize() tea.Cmd {
+	return tea.Tick(windowSizeTimeout, func(t time.Time) tea.Msg {
+		return windowSizeTimeoutMsg{}
+	})
+}
+
+// handleWindowSizeTimeout assumes the default size if the terminal still hasn't reported one
+func (m Model) handleWindowSizeTimeout() (tea.Model, tea.Cmd) {
+	if m.width != 0 {
+		return m, nil
+	}
+
+	m.AddWarningMessage("The terminal did not report its size, assuming 80x24", "system")
+	return m.Update(tea.WindowSizeMsg{Width: defaultWidth, Height: defaultHeight})
+}
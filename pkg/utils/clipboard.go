@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands lists the supported clipboard tools in order of preference
+var clipboardCommands = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+}
+
+// CopyToClipboard copies text to the system clipboard using wl-copy or xclip
+func CopyToClipboard(text string) error {
+	// Prefer wl-copy on Wayland sessions, otherwise fall back to the first available tool
+	candidates := clipboardCommands
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		candidates = [][]string{clipboardCommands[1], clipboardCommands[0]}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+
+		cmd := exec.Command(candidate[0], candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to copy to clipboard with %s: %w", candidate[0], err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no clipboard tool found (install wl-clipboard or xclip)")
+}
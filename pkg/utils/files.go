@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 )
 
@@ -108,27 +111,35 @@ func CopyDirWithLowMemory(src, dst string) error {
 	// Create a WaitGroup to wait for all workers to finish
 	var wg sync.WaitGroup
 
-	// Create a channel to collect errors
-	errCh := make(chan error, numWorkers)
+	// Record the first error and tell everyone else to stop
+	var (
+		errOnce  sync.Once
+		firstErr error
+		failed   = make(chan struct{})
+	)
+	setErr := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			close(failed)
+		})
+	}
 
 	// Start workers
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			// Keep draining the channel after a failure so the walk never blocks
 			for task := range tasks {
-				if task.isDir {
-					// Create the directory
-					if err := os.MkdirAll(task.dst, task.mode); err != nil {
-						errCh <- fmt.Errorf("failed to create directory %s: %w", task.dst, err)
-						return
-					}
-				} else {
-					// Copy the file with a small buffer to reduce memory usage
-					if err := copyFileWithSmallBuffer(task.src, task.dst, task.mode); err != nil {
-						errCh <- fmt.Errorf("failed to copy file %s: %w", task.src, err)
-						return
-					}
+				select {
+				case <-failed:
+					continue
+				default:
+				}
+
+				// Copy the file with a small buffer to reduce memory usage
+				if err := copyFileWithSmallBuffer(task.src, task.dst, task.mode); err != nil {
+					setErr(fmt.Errorf("failed to copy file %s: %w", task.src, err))
 				}
 			}
 		}()
@@ -153,15 +164,25 @@ func CopyDirWithLowMemory(src, dst string) error {
 			return nil
 		}
 
-		// Send a task to the worker pool
-		tasks <- copyTask{
-			src:   path,
-			dst:   dstPath,
-			isDir: info.IsDir(),
-			mode:  info.Mode(),
+		// Create directories here so they exist before any worker copies into them
+		if info.IsDir() {
+			if err := os.MkdirAll(dstPath, info.Mode()); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", dstPath, err)
+			}
+			return nil
 		}
 
-		return nil
+		// Send a task to the worker pool, or stop once a worker has failed
+		select {
+		case tasks <- copyTask{
+			src:  path,
+			dst:  dstPath,
+			mode: info.Mode(),
+		}:
+			return nil
+		case <-failed:
+			return filepath.SkipAll
+		}
 	})
 
 	// Close the tasks channel to signal workers to exit
@@ -170,23 +191,19 @@ func CopyDirWithLowMemory(src, dst string) error {
 	// Wait for all workers to finish
 	wg.Wait()
 
-	// Check if there were any errors
-	select {
-	case err := <-errCh:
-		return err
-	default:
-		// No errors
+	// A worker error takes precedence over the walk being cut short
+	if firstErr != nil {
+		return firstErr
 	}
 
 	return err
 }
 
-// copyTask represents a file or directory copy task
+// copyTask represents a file copy task
 type copyTask struct {
-	src   string
-	dst   string
-	isDir bool
-	mode  os.FileMode
+	src  string
+	dst  string
+	mode os.FileMode
 }
 
 // copyFileWithSmallBuffer copies a file from src to dst using a small buffer
@@ -239,3 +256,39 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// RsyncAvailable reports whether the rsync binary is installed
+func RsyncAvailable() bool {
+	_, err := exec.LookPath("rsync")
+	return err == nil
+}
+
+// CopyDirWithRsync copies the contents of src into dst with rsync -a, passing
+// each line of rsync's output to onOutput
+func CopyDirWithRsync(src, dst string, onOutput func(string)) error {
+	// Trailing slashes make rsync copy the contents of src rather than src itself
+	cmd := exec.Command("rsync", "-a", "--info=stats1", src+string(filepath.Separator), dst+string(filepath.Separator))
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start rsync: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			onOutput(line)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("rsync failed to copy %s: %w", src, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// copyFuncs are the directory copies that must produce the same trees
+var copyFuncs = map[string]func(src, dst string) error{
+	"CopyDir":              CopyDir,
+	"CopyDirWithLowMemory": CopyDirWithLowMemory,
+}
+
+// writeFile creates a file with its parent directories, failing the test on errors
+func writeFile(t *testing.T, path string, data []byte, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, mode); err != nil {
+		t.Fatal(err)
+	}
+	// WriteFile applies the umask
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testTree creates a source tree with nested and empty directories, files
+// with different modes and a file larger than the copy buffer
+func testTree(t *testing.T) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "src")
+
+	writeFile(t, filepath.Join(src, "top.txt"), []byte("top\n"), 0644)
+	writeFile(t, filepath.Join(src, "bin", "script.sh"), []byte("#!/bin/sh\necho hi\n"), 0755)
+	writeFile(t, filepath.Join(src, "a", "b", "c", "deep.conf"), []byte("deep = true\n"), 0600)
+	writeFile(t, filepath.Join(src, "a", "b", "sibling.conf"), []byte("sibling\n"), 0640)
+
+	// Not a multiple of the 4KB buffer, so the last read is partial
+	large := make([]byte, 3<<20+123)
+	rand.New(rand.NewSource(1)).Read(large)
+	writeFile(t, filepath.Join(src, "large.bin"), large, 0644)
+
+	if err := os.MkdirAll(filepath.Join(src, "empty", "nested-empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(src, "private"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(src, "private"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	return src
+}
+
+// treeEntry is a file or directory of a tree as compared by the tests
+type treeEntry struct {
+	mode os.FileMode
+	data []byte
+}
+
+// readTree returns the entries below root by their relative path
+func readTree(t *testing.T, root string) map[string]treeEntry {
+	t.Helper()
+	tree := make(map[string]treeEntry)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		entry := treeEntry{mode: info.Mode()}
+		if !info.IsDir() {
+			if entry.data, err = os.ReadFile(path); err != nil {
+				return err
+			}
+		}
+		tree[rel] = entry
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// compareTrees reports every difference between the trees below want and got
+func compareTrees(t *testing.T, want, got string) {
+	t.Helper()
+	wantTree, gotTree := readTree(t, want), readTree(t, got)
+
+	for rel, w := range wantTree {
+		g, ok := gotTree[rel]
+		switch {
+		case !ok:
+			t.Errorf("%s is missing from the copy", rel)
+		case g.mode != w.mode:
+			t.Errorf("%s has mode %v, want %v", rel, g.mode, w.mode)
+		case !bytes.Equal(g.data, w.data):
+			t.Errorf("%s has %d bytes that differ from the %d of the original", rel, len(g.data), len(w.data))
+		}
+	}
+	for rel := range gotTree {
+		if _, ok := wantTree[rel]; !ok {
+			t.Errorf("%s is in the copy but not the original", rel)
+		}
+	}
+}
+
+func TestCopyDir(t *testing.T) {
+	src := testTree(t)
+	for name, copyDir := range copyFuncs {
+		t.Run(name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "dst")
+			if err := copyDir(src, dst); err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			compareTrees(t, src, dst)
+		})
+	}
+}
+
+func TestCopyDirImplementationsMatch(t *testing.T) {
+	src := testTree(t)
+	dst := t.TempDir()
+
+	plain, lowMemory := filepath.Join(dst, "plain"), filepath.Join(dst, "low-memory")
+	if err := CopyDir(src, plain); err != nil {
+		t.Fatalf("CopyDir() error = %v", err)
+	}
+	if err := CopyDirWithLowMemory(src, lowMemory); err != nil {
+		t.Fatalf("CopyDirWithLowMemory() error = %v", err)
+	}
+	compareTrees(t, plain, lowMemory)
+}
+
+func TestCopyDirMissingSource(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	for name, copyDir := range copyFuncs {
+		t.Run(name, func(t *testing.T) {
+			err := copyDir(missing, filepath.Join(t.TempDir(), "dst"))
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("%s() error = %v, want one wrapping fs.ErrNotExist", name, err)
+			}
+		})
+	}
+}
+
+func TestCopyDirUnreadableSource(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	writeFile(t, filepath.Join(src, "readable.txt"), []byte("fine\n"), 0644)
+
+	// A dangling symlink can't be opened, even by root
+	if err := os.Symlink(filepath.Join(src, "nowhere"), filepath.Join(src, "broken")); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, copyDir := range copyFuncs {
+		t.Run(name, func(t *testing.T) {
+			err := copyDir(src, filepath.Join(t.TempDir(), "dst"))
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("%s() error = %v, want the broken link's fs.ErrNotExist", name, err)
+			}
+		})
+	}
+}
+
+func TestCopyDirPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read files without read permission")
+	}
+
+	src := filepath.Join(t.TempDir(), "src")
+	writeFile(t, filepath.Join(src, "secret"), []byte("secret\n"), 0000)
+
+	for name, copyDir := range copyFuncs {
+		t.Run(name, func(t *testing.T) {
+			err := copyDir(src, filepath.Join(t.TempDir(), "dst"))
+			if !errors.Is(err, fs.ErrPermission) {
+				t.Errorf("%s() error = %v, want one wrapping fs.ErrPermission", name, err)
+			}
+		})
+	}
+}
+
+func TestCopyDirWithLowMemoryStopsAfterError(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+
+	// Many more files than the task buffer holds, with every file after the
+	// first few hundred failing, so a worker stopping at its first error
+	// would leave the walk blocked on a full channel
+	for i := 0; i < 300; i++ {
+		writeFile(t, filepath.Join(src, fmt.Sprintf("a-%03d.txt", i)), []byte("fine\n"), 0644)
+	}
+	for i := 0; i < 1000; i++ {
+		if err := os.Symlink(filepath.Join(src, "nowhere"), filepath.Join(src, fmt.Sprintf("b-%04d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- CopyDirWithLowMemory(src, filepath.Join(t.TempDir(), "dst"))
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("CopyDirWithLowMemory() error = %v, want the broken links' fs.ErrNotExist", err)
+		}
+		if !strings.Contains(err.Error(), filepath.Join(src, "b-")) {
+			t.Errorf("CopyDirWithLowMemory() error = %v, want it to name a broken link", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("CopyDirWithLowMemory() hung after a worker error")
+	}
+}